@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/evaluator"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/k8s"
+	"go.uber.org/zap"
+)
+
+func runCleanup(ctx context.Context, logger *zap.SugaredLogger, args []string) int {
+	flags := flag.NewFlagSet("cleanup", flag.ExitOnError)
+	configFile := flags.String("config", "config.yaml", "(optional) absolute path to the config file")
+	olderThan := flags.Duration("older-than", time.Hour, "(optional) only remove resources older than this age")
+	dryRun := flags.Bool("dry-run", false, "(optional) only show the resources which would be removed")
+	overrides := config.AddOverrideFlags(flags)
+	addFlagAliases(flags, overrides, clientFlagAliases)
+	err := flags.Parse(args)
+	if err != nil {
+		logger.Errorw("failed to parse flags", "error", err)
+		return 1
+	}
+
+	config, err := config.Read(*configFile, overrides.Overrides()...)
+	if err != nil {
+		logger.Errorw("failed to read Config", "error", err)
+		return 1
+	}
+	k8sClient, err := k8s.NewFromOptions(evaluator.ClientOptions(config, nil))
+	if err != nil {
+		logger.Errorw("failed to create kubernetes client", "error", err)
+		return 1
+	}
+
+	gc := evaluator.NewGarbageCollector(k8sClient, logger)
+	resources, err := gc.FindLeakedResources(ctx, *olderThan)
+	if err != nil {
+		logger.Errorw("Failed to find leaked resources", "error", err)
+		return 1
+	}
+	if len(resources) == 0 {
+		logger.Infow("No leaked resources found", "olderThan", *olderThan)
+		return 0
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 1, 1, 3, ' ', 0)
+	_, err = fmt.Fprintln(w, "KIND\tNAMESPACE\tNAME\tRUN ID\tAGE\t")
+	if err != nil {
+		logger.Errorw("Failed to print leaked resources", "error", err)
+		return 1
+	}
+	for _, resource := range resources {
+		_, err = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t\n", resource.Kind, resource.Namespace, resource.Name, resource.RunID,
+			time.Since(resource.CreationTimestamp).Round(time.Second))
+		if err != nil {
+			logger.Errorw("Failed to print leaked resources", "error", err)
+			return 1
+		}
+	}
+	err = w.Flush()
+	if err != nil {
+		logger.Errorw("Failed to print leaked resources", "error", err)
+		return 1
+	}
+
+	if *dryRun {
+		return 0
+	}
+	err = gc.DeleteLeakedResources(ctx, resources)
+	if err != nil {
+		logger.Errorw("Failed to delete leaked resources", "error", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/history"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/reports"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/reports/writer"
+	"go.uber.org/zap"
+)
+
+func runCompare(ctx context.Context, logger *zap.SugaredLogger, args []string) int {
+	flags := flag.NewFlagSet("compare", flag.ExitOnError)
+	configFile := flags.String("config", "config.yaml", "(optional) absolute path to the config file")
+	failOnRegression := flags.Bool("fail-on-regression", true, "(optional) exit with a non-zero exit code if any regressions are found")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(flags.Output(), "Usage: test-runner compare [flags] [JSON reports, from the oldest to the newest]\n")
+		flags.PrintDefaults()
+	}
+	overrides := config.AddOverrideFlags(flags)
+	addFlagAliases(flags, overrides, compareFlagAliases)
+	err := flags.Parse(args)
+	if err != nil {
+		logger.Errorw("failed to parse flags", "error", err)
+		return 1
+	}
+
+	config, err := config.Read(*configFile, overrides.Overrides()...)
+	if err != nil {
+		logger.Errorw("failed to read Config", "error", err)
+		return 1
+	}
+
+	runs, err := readRuns(config, flags.Args())
+	if err != nil {
+		logger.Errorw("Failed to read runs", "error", err)
+		return 1
+	}
+	if len(runs) < config.History.Regression.MinBaselineRuns+1 {
+		logger.Warnw("Not enough runs to compare against a baseline", "runs", len(runs),
+			"minBaselineRuns", config.History.Regression.MinBaselineRuns)
+	}
+	regressions := reports.DetectRegressions(runs, config.History.Regression)
+
+	w, err := writer.ResolveWriter(reportFormat(config), writer.Options{})
+	if err != nil {
+		logger.Errorw("Failed to resolve a writer", "error", err)
+		return 1
+	}
+	err = w.WriteRegressions(regressions, os.Stdout)
+	if err != nil {
+		logger.Errorw("Failed to print regressions", "error", err)
+		return 1
+	}
+
+	if len(regressions) > 0 && *failOnRegression {
+		logger.Errorw("Nodes regressed compared to their baseline", "regressions", len(regressions))
+		return 1
+	}
+	return 0
+}
+
+// readRuns reads the runs from the JSON reports, or from the history if no reports are provided.
+func readRuns(config *config.Config, reportFiles []string) ([][]*reports.TestSuiteResult, error) {
+	if len(reportFiles) == 0 {
+		if config.History.Path == "" {
+			return nil, fmt.Errorf("either JSON reports or the history (history.path or the -history flag) is required")
+		}
+		store, err := history.Open(config.History.Path)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = store.Close()
+		}()
+		return history.RunResults(store, resolveClusterName(config, ""))
+	}
+
+	runs := [][]*reports.TestSuiteResult{}
+	for _, reportFile := range reportFiles {
+		file, err := os.Open(reportFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open report %s: %w", reportFile, err)
+		}
+		fileRuns, err := reports.ReadResults(file)
+		_ = file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read report %s: %w", reportFile, err)
+		}
+		runs = append(runs, fileRuns...)
+	}
+	return runs, nil
+}
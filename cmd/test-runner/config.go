@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+	"go.uber.org/zap"
+)
+
+func runConfig(_ context.Context, logger *zap.SugaredLogger, args []string) int {
+	if len(args) == 0 || args[0] != "print" {
+		logger.Errorw("Unknown config subcommand, expected print", "args", args)
+		return 1
+	}
+
+	flags := flag.NewFlagSet("config print", flag.ExitOnError)
+	configFile := flags.String("config", "config.yaml", "(optional) absolute path to the config file")
+	overrides := config.AddOverrideFlags(flags)
+	// The shorthand flags of all the subcommands are accepted so that their effect can be checked
+	addFlagAliases(flags, overrides, clientFlagAliases, runFlagAliases, serveFlagAliases, watchFlagAliases,
+		compareFlagAliases)
+	err := flags.Parse(args[1:])
+	if err != nil {
+		logger.Errorw("failed to parse flags", "error", err)
+		return 1
+	}
+
+	c, err := config.Read(*configFile, overrides.Overrides()...)
+	if err != nil {
+		logger.Errorw("failed to read Config", "error", err)
+		return 1
+	}
+	err = config.Write(c, os.Stdout)
+	if err != nil {
+		logger.Errorw("Failed to print config", "error", err)
+		return 1
+	}
+	return 0
+}
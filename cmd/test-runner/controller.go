@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/controller"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/evaluator"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/k8s"
+	"go.uber.org/zap"
+	"k8s.io/client-go/dynamic"
+)
+
+func runController(ctx context.Context, logger *zap.SugaredLogger, args []string) int {
+	flags := flag.NewFlagSet("controller", flag.ExitOnError)
+	configFile := flags.String("config", "", "(optional) absolute path to a config file with the client settings")
+	overrides := &config.FlagOverrides{}
+	addFlagAliases(flags, overrides, clientFlagAliases)
+	err := flags.Parse(args)
+	if err != nil {
+		logger.Errorw("failed to parse flags", "error", err)
+		return 1
+	}
+
+	// The evaluations are configured by their specs, and hence only the client is configured by the config file,
+	// the environment variables and the flags
+	var clientConfig *config.Config
+	if *configFile != "" {
+		clientConfig, err = config.Read(*configFile, overrides.Overrides()...)
+	} else {
+		clientConfig, err = config.ReadDefaults(overrides.Overrides()...)
+	}
+	if err != nil {
+		logger.Errorw("failed to read Config", "error", err)
+		return 1
+	}
+	// The in-cluster config is used when there is no kubeconfig
+	restConfig, err := k8s.NewRESTConfig(evaluator.ClientOptions(clientConfig, nil))
+	if err != nil {
+		logger.Errorw("failed to create k8s config", "error", err)
+		return 1
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		logger.Errorw("failed to create dynamic client", "error", err)
+		return 1
+	}
+	k8sClient, err := k8s.NewFromRESTConfig(restConfig)
+	if err != nil {
+		logger.Errorw("failed to create kubernetes client", "error", err)
+		return 1
+	}
+
+	c := controller.New(dynamicClient, func(config *config.Config) evaluator.TestRunnerInterface {
+		return evaluator.NewTestRunnerWithClients(config, logger, k8sClient, &http.Client{
+			Timeout: time.Minute,
+		})
+	}, logger)
+	err = c.Run(ctx)
+	if err != nil {
+		logger.Errorw("Failed to run controller", "error", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+)
+
+// flagAlias is a shorthand flag of the subcommands which overrides the config field at the path, in the same way
+// as the flag named after the path.
+type flagAlias struct {
+	name  string
+	path  string
+	usage string
+}
+
+var clientFlagAliases = []flagAlias{
+	{name: "kubeconfig", path: "kubeConfig", usage: "kubeconfig files to merge, separated like KUBECONFIG"},
+	{name: "context", path: "client.context", usage: "context of the kubeconfig to use"},
+	{name: "as", path: "client.impersonate.user", usage: "user to impersonate"},
+	{name: "as-group", path: "client.impersonate.groups", usage: "comma separated groups to impersonate"},
+	{name: "qps", path: "client.qps", usage: "maximum queries per second to the API server"},
+	{name: "burst", path: "client.burst", usage: "maximum burst of queries to the API server"},
+	{name: "request-timeout", path: "client.timeout", usage: "timeout of a single request to the API server"},
+}
+
+var runFlagAliases = []flagAlias{
+	{name: "force-namespace-deletion", path: "forceNamespaceDeletion", usage: "delete the namespace even if it was not created by the evaluator"},
+	{name: "remediate", path: "remediation.enabled", usage: "take the configured remediation actions on the nodes failing the thresholds"},
+	{name: "remediation-dry-run", path: "remediation.dryRun", usage: "only show the remediation actions which would be taken (set to false to take them)"},
+	{name: "history", path: "history.path", usage: "path to the database file to store the results in"},
+}
+
+var serveFlagAliases = []flagAlias{
+	{name: "listen", path: "serve.listenAddress", usage: "address to serve the HTTP API on"},
+	{name: "interval", path: "serve.interval", usage: "interval between scheduled runs"},
+	{name: "store-dir", path: "serve.storeDirectory", usage: "directory to persist the runs in"},
+}
+
+var watchFlagAliases = []flagAlias{
+	{name: "taint-unevaluated", path: "watch.taintUnevaluated", usage: "taint the new nodes until they pass the evaluation"},
+}
+
+var historyFlagAliases = []flagAlias{
+	{name: "history", path: "history.path", usage: "path to the database file"},
+	{name: "cluster", path: "history.cluster", usage: "name of the cluster in the history"},
+}
+
+var compareFlagAliases = []flagAlias{
+	{name: "history", path: "history.path", usage: "path to the database file to read the runs from when no reports are provided"},
+	{name: "cluster", path: "history.cluster", usage: "name of the cluster in the history"},
+	{name: "format", path: "report.format", usage: "format of the output (text or json)"},
+}
+
+// addFlagAliases adds the shorthand flags to the flag set. An alias shared by multiple subcommands is only added once.
+func addFlagAliases(flags *flag.FlagSet, overrides *config.FlagOverrides, aliases ...[]flagAlias) {
+	for _, subcommandAliases := range aliases {
+		for _, alias := range subcommandAliases {
+			if flags.Lookup(alias.name) != nil {
+				continue
+			}
+			overrides.AddAlias(flags, alias.name, alias.path, fmt.Sprintf("(optional) %s (overrides %s)", alias.usage, alias.path))
+		}
+	}
+}
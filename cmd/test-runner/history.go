@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/history"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/reports"
+	"go.uber.org/zap"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	simulatedClusterName = "simulation"
+	defaultClusterName   = "default"
+)
+
+func runHistory(ctx context.Context, logger *zap.SugaredLogger, args []string) int {
+	flags := flag.NewFlagSet("history", flag.ExitOnError)
+	configFile := flags.String("config", "config.yaml", "(optional) absolute path to the config file")
+	nodeName := flags.String("node", "", "(optional) show the history of the node instead of the degrading nodes")
+	overrides := config.AddOverrideFlags(flags)
+	addFlagAliases(flags, overrides, historyFlagAliases)
+	err := flags.Parse(args)
+	if err != nil {
+		logger.Errorw("failed to parse flags", "error", err)
+		return 1
+	}
+
+	config, err := config.Read(*configFile, overrides.Overrides()...)
+	if err != nil {
+		logger.Errorw("failed to read Config", "error", err)
+		return 1
+	}
+	if config.History.Path == "" {
+		logger.Errorw("history.path or the -history flag is required")
+		return 1
+	}
+
+	store, err := history.Open(config.History.Path)
+	if err != nil {
+		logger.Errorw("Failed to open history", "error", err)
+		return 1
+	}
+	defer func() {
+		err = store.Close()
+		if err != nil {
+			logger.Warnw("Failed to close history", "error", err)
+		}
+	}()
+	clusterName := resolveClusterName(config, "")
+
+	if *nodeName != "" {
+		records, err := store.NodeHistory(clusterName, *nodeName)
+		if err != nil {
+			logger.Errorw("Failed to read node history", "error", err)
+			return 1
+		}
+		if len(records) == 0 {
+			logger.Errorw("No results found for the node", "cluster", clusterName, "node", *nodeName)
+			return 1
+		}
+		err = history.WriteNodeHistory(records, os.Stdout)
+		if err != nil {
+			logger.Errorw("Failed to print node history", "error", err)
+			return 1
+		}
+		degradations := history.DetectDegradation(records, config.History.Degradation)
+		if len(degradations) > 0 {
+			_, err = fmt.Fprintln(os.Stdout)
+			if err == nil {
+				err = history.WriteDegradations(degradations, os.Stdout)
+			}
+			if err != nil {
+				logger.Errorw("Failed to print degradations", "error", err)
+				return 1
+			}
+		}
+		return 0
+	}
+
+	nodes, err := store.Nodes(clusterName)
+	if err != nil {
+		logger.Errorw("Failed to read nodes", "error", err)
+		return 1
+	}
+	degradations := []*history.Degradation{}
+	for _, node := range nodes {
+		records, err := store.NodeHistory(clusterName, node)
+		if err != nil {
+			logger.Errorw("Failed to read node history", "error", err)
+			return 1
+		}
+		degradations = append(degradations, history.DetectDegradation(records, config.History.Degradation)...)
+	}
+	if len(degradations) == 0 {
+		logger.Infow("No nodes are degrading", "cluster", clusterName, "nodes", len(nodes))
+		return 0
+	}
+	err = history.WriteDegradations(degradations, os.Stdout)
+	if err != nil {
+		logger.Errorw("Failed to print degradations", "error", err)
+		return 1
+	}
+	return 0
+}
+
+// saveHistory stores the results of a completed run in the history database under the ID of the run.
+func saveHistory(logger *zap.SugaredLogger, config *config.Config, scenarioFile string, clients []*clusterClients,
+	runID string, runStartTime time.Time, testRunResults []*reports.TestSuiteResult) error {
+	store, err := history.Open(config.History.Path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = store.Close()
+		if err != nil {
+			logger.Warnw("Failed to close history", "error", err)
+		}
+	}()
+	if len(config.Clusters) == 0 {
+		clusterName := resolveClusterName(config, scenarioFile)
+		err = store.Save(clusterName, runID, runStartTime, testRunResults)
+		if err != nil {
+			return err
+		}
+		logger.Infow("Saved results to history", "cluster", clusterName, "run", runID)
+		return nil
+	}
+	// The results of each cluster are kept under its own name so that the nodes are compared within the cluster
+	for _, c := range clients {
+		err = store.Save(c.cluster, runID, runStartTime, reports.FilterByCluster(testRunResults, c.cluster))
+		if err != nil {
+			return err
+		}
+		logger.Infow("Saved results to history", "cluster", c.cluster, "run", runID)
+	}
+	return nil
+}
+
+// resolveClusterName returns the configured cluster name, or the context of the kubeconfig in use.
+func resolveClusterName(config *config.Config, scenarioFile string) string {
+	if config.History.Cluster != "" {
+		return config.History.Cluster
+	}
+	if scenarioFile != "" {
+		return simulatedClusterName
+	}
+	if config.Client.Context != "" {
+		return config.Client.Context
+	}
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if config.KubeConfig != "" {
+		loadingRules.Precedence = filepath.SplitList(config.KubeConfig)
+	}
+	kubeConfig, err := loadingRules.Load()
+	if err != nil || kubeConfig.CurrentContext == "" {
+		return defaultClusterName
+	}
+	return kubeConfig.CurrentContext
+}
@@ -3,28 +3,43 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"io"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
 	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/evaluator"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/k8s"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/remediation"
 	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/reports"
 	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/reports/writer"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/simulation"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
 func main() {
-	ctx := context.Background()
+	os.Exit(run())
+}
+
+func run() int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	zapConf := zap.NewDevelopmentConfig()
 	zapConf.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
 	zapLogger, err := zapConf.Build()
 	if err != nil {
 		log.Printf("Failed to create logger: %v", err)
-		return
+		return 1
 	}
 	defer func() {
 		err = zapLogger.Sync()
@@ -35,50 +50,296 @@ func main() {
 	logger := zapLogger.Sugar()
 	logger.Info("Starting Node Performance Evaluator")
 
-	configFile := flag.String("config", "config.yaml", "(optional) absolute path to the config file")
-	flag.Parse()
+	args := os.Args[1:]
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		// Running the test without a subcommand is kept for compatibility
+		return runTest(ctx, logger, args)
+	}
+	switch args[0] {
+	case "run":
+		return runTest(ctx, logger, args[1:])
+	case "render":
+		return runRender(ctx, logger, args[1:])
+	case "cleanup":
+		return runCleanup(ctx, logger, args[1:])
+	case "serve":
+		return runServe(ctx, logger, args[1:])
+	case "watch":
+		return runWatch(ctx, logger, args[1:])
+	case "history":
+		return runHistory(ctx, logger, args[1:])
+	case "compare":
+		return runCompare(ctx, logger, args[1:])
+	case "controller":
+		return runController(ctx, logger, args[1:])
+	case "config":
+		return runConfig(ctx, logger, args[1:])
+	default:
+		logger.Errorw("Unknown subcommand", "subcommand", args[0],
+			"subcommands", "run, render, compare, cleanup, history, serve, watch, controller, config")
+		return 1
+	}
+}
 
-	config, err := config.Read(*configFile)
+func runTest(ctx context.Context, logger *zap.SugaredLogger, args []string) int {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	configFile := flags.String("config", "config.yaml", "(optional) absolute path to the config file")
+	dryRun := flags.Bool("dry-run", false, "(optional) print the kubernetes manifests without creating them")
+	serverDryRun := flags.Bool("server-dry-run", false, "(optional) validate the printed manifests using a server-side dry run (requires -dry-run)")
+	simulate := flags.String("simulate", "", "(optional) path to a scenario file for running against a simulated cluster")
+	preflightOnly := flags.Bool("preflight", false, "(optional) only run the preflight checks and exit")
+	skipPreflight := flags.Bool("skip-preflight", false, "(optional) skip the preflight checks before running the test")
+	overrides := config.AddOverrideFlags(flags)
+	addFlagAliases(flags, overrides, clientFlagAliases, runFlagAliases)
+	err := flags.Parse(args)
 	if err != nil {
-		logger.Fatalw("failed to read Config", "error", err)
+		logger.Errorw("failed to parse flags", "error", err)
+		return 1
 	}
 
-	testRunner, err := evaluator.NewTestRunner(config, logger)
+	config, err := config.Read(*configFile, overrides.Overrides()...)
 	if err != nil {
-		logger.Fatalw("failed to create test runner", "error", err)
+		logger.Errorw("failed to read Config", "error", err)
+		return 1
 	}
-	testRun, err := testRunner.RunTest(ctx)
+
+	clients, err := newClusterClients(logger, config, *simulate)
 	if err != nil {
-		logger.Fatalw("Failed to run test", "error", err)
+		logger.Errorw("failed to create clients", "error", err)
+		return 1
 	}
+	testRunner := newTestRunner(config, logger, clients)
 
-	testRunResults := reports.CalculateTestSuiteResults(testRun)
-	writerType := os.Getenv("TEST_RUNNER_REPORT_FORMAT")
-	if writerType == "" {
-		writerType = "text"
+	if *dryRun {
+		return renderManifests(ctx, logger, testRunner, *serverDryRun)
+	}
+
+	if *preflightOnly || !*skipPreflight {
+		checks := testRunner.RunPreflightChecks(ctx)
+		checksOutput := os.Stderr
+		if *preflightOnly {
+			checksOutput = os.Stdout
+		}
+		err = evaluator.WritePreflightChecks(checks, checksOutput)
+		if err != nil {
+			logger.Errorw("Failed to print preflight checks", "error", err)
+			return 1
+		}
+		if !evaluator.PreflightChecksPassed(checks) {
+			logger.Errorw("Preflight checks failed")
+			return 1
+		}
+		if *preflightOnly {
+			return 0
+		}
 	}
-	writer, err := writer.ResolveWriter(writerType)
+
+	runStartTime := time.Now()
+	testRun, runErr := testRunner.RunTest(ctx)
+	if runErr != nil {
+		if len(testRun) == 0 {
+			logger.Errorw("Failed to run test", "error", runErr)
+			return 1
+		}
+		logger.Warnw("Test run did not complete, reporting only the completed test suites", "error", runErr)
+	}
+
+	testRunResults := reports.CalculateTestSuiteResults(testRun)
+	writer, err := writer.ResolveWriter(reportFormat(config), writer.Options{
+		Columns: config.Report.Columns,
+		GroupBy: config.Report.GroupBy,
+	})
 	if err != nil {
-		logger.Fatalw("Failed to resolve a writer", "error", err)
+		logger.Errorw("Failed to resolve a writer", "error", err)
+		return 1
 	}
 
 	outputWriters := []io.Writer{os.Stdout}
-	if outputFile := os.Getenv("TEST_RUNNER_REPORT_FILE"); outputFile != "" {
+	if outputFile := reportFile(config); outputFile != "" {
 		err = os.MkdirAll(filepath.Dir(outputFile), 0755)
 		if err != nil {
-			logger.Fatalw("Failed to create output file parent directory", "error", err)
+			logger.Errorw("Failed to create output file parent directory", "error", err)
+			return 1
 		}
 
 		var fileWriter *os.File
 		fileWriter, err = os.OpenFile(outputFile, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
 		if err != nil {
-			logger.Fatalw("Failed to create output file", "error", err)
+			logger.Errorw("Failed to create output file", "error", err)
+			return 1
 		}
+		defer func() {
+			err = fileWriter.Close()
+			if err != nil {
+				logger.Warnw("Failed to close output file", "error", err)
+			}
+		}()
 		outputWriters = append(outputWriters, fileWriter)
 	}
 
 	err = writer.Write(testRunResults, io.MultiWriter(outputWriters...))
 	if err != nil {
-		logger.Fatalw("Failed to print report", "error", err)
+		logger.Errorw("Failed to print report", "error", err)
+		return 1
+	}
+
+	if runErr != nil {
+		logger.Errorw("Failed to run test", "error", runErr)
+		return 1
+	}
+
+	if config.History.Path != "" {
+		err = saveHistory(logger, config, *simulate, clients, testRunner.RunID(), runStartTime, testRunResults)
+		if err != nil {
+			logger.Errorw("Failed to save results to history", "error", err)
+			return 1
+		}
+	}
+
+	if config.Remediation.Enabled {
+		// The nodes of all the clusters are planned together so that the cap on the number of nodes is global
+		k8sClients := map[string]k8s.Interface{}
+		for _, c := range clients {
+			k8sClients[c.cluster] = c.k8sClient
+		}
+		err = remediateNodes(ctx, logger, k8sClients, config, testRunResults)
+		if err != nil {
+			logger.Errorw("Failed to remediate nodes", "error", err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// reportFormat returns the configured format of the report, falling back to the TEST_RUNNER_REPORT_FORMAT
+// environment variable used before the format could be configured.
+func reportFormat(config *config.Config) string {
+	if config.Report.Format != "" {
+		return config.Report.Format
+	}
+	if format := os.Getenv("TEST_RUNNER_REPORT_FORMAT"); format != "" {
+		return format
+	}
+	return "text"
+}
+
+// reportFile returns the configured report file, falling back to the TEST_RUNNER_REPORT_FILE environment variable.
+func reportFile(config *config.Config) string {
+	if config.Report.File != "" {
+		return config.Report.File
+	}
+	return os.Getenv("TEST_RUNNER_REPORT_FILE")
+}
+
+// clusterClients are the clients for accessing one of the evaluated clusters. The cluster is empty when
+// only the current context of the kubeconfig is evaluated.
+type clusterClients struct {
+	cluster    string
+	k8sClient  k8s.Interface
+	httpClient *http.Client
+}
+
+// newClusterClients creates the clients for accessing each of the configured clusters, or the current context
+// of the kubeconfig if no clusters are configured. Each cluster gets its own simulated cluster if a scenario
+// file is provided.
+func newClusterClients(logger *zap.SugaredLogger, config *config.Config, scenarioFile string) ([]*clusterClients, error) {
+	if len(config.Clusters) == 0 {
+		k8sClient, httpClient, err := newClients(logger, config, scenarioFile)
+		if err != nil {
+			return nil, err
+		}
+		return []*clusterClients{{k8sClient: k8sClient, httpClient: httpClient}}, nil
+	}
+
+	var scenario *simulation.Scenario
+	if scenarioFile != "" {
+		var err error
+		scenario, err = simulation.ReadScenario(scenarioFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read simulation scenario: %w", err)
+		}
+		logger.Infow("running against simulated clusters", "scenario", scenarioFile, "clusters", len(config.Clusters))
+	}
+	clients := []*clusterClients{}
+	for _, cluster := range config.Clusters {
+		if scenario != nil {
+			k8sClient, httpClient := simulation.New(scenario)
+			clients = append(clients, &clusterClients{
+				cluster:    cluster.Name,
+				k8sClient:  k8sClient,
+				httpClient: httpClient,
+			})
+			continue
+		}
+		k8sClient, err := k8s.NewFromOptions(evaluator.ClientOptions(config, &cluster))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create kubernetes client of cluster %s: %w", cluster.Name, err)
+		}
+		clients = append(clients, &clusterClients{
+			cluster:   cluster.Name,
+			k8sClient: k8sClient,
+			httpClient: &http.Client{
+				Timeout: time.Minute,
+			},
+		})
+	}
+	return clients, nil
+}
+
+// newTestRunner creates a test runner for a single run, evaluating all the clusters together if multiple
+// clusters are configured.
+func newTestRunner(config *config.Config, logger *zap.SugaredLogger, clients []*clusterClients) evaluator.TestRunnerInterface {
+	if len(config.Clusters) == 0 {
+		return evaluator.NewTestRunnerWithClients(config, logger, clients[0].k8sClient, clients[0].httpClient)
+	}
+	runID := uuid.New().String()
+	runners := []*evaluator.ClusterTestRunner{}
+	for _, c := range clients {
+		runners = append(runners, &evaluator.ClusterTestRunner{
+			Cluster: c.cluster,
+			Runner: evaluator.NewTestRunnerWithRunID(runID, config, logger.With("cluster", c.cluster), c.k8sClient,
+				c.httpClient),
+		})
+	}
+	return evaluator.NewMultiClusterTestRunner(runID, runners, logger)
+}
+
+// newClients creates the clients for accessing the cluster, or a simulated cluster if a scenario file is provided.
+func newClients(logger *zap.SugaredLogger, config *config.Config, scenarioFile string) (k8s.Interface, *http.Client, error) {
+	if scenarioFile != "" {
+		scenario, err := simulation.ReadScenario(scenarioFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read simulation scenario: %w", err)
+		}
+		logger.Infow("running against a simulated cluster", "scenario", scenarioFile)
+		k8sClient, httpClient := simulation.New(scenario)
+		return k8sClient, httpClient, nil
+	}
+	k8sClient, err := k8s.NewFromOptions(evaluator.ClientOptions(config, nil))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+	return k8sClient, &http.Client{
+		Timeout: time.Minute,
+	}, nil
+}
+
+// remediateNodes takes the remediation actions on the nodes which failed the thresholds after printing them.
+func remediateNodes(ctx context.Context, logger *zap.SugaredLogger, k8sClients map[string]k8s.Interface,
+	config *config.Config, testRunResults []*reports.TestSuiteResult) error {
+	r := remediation.NewMultiClusterRemediator(k8sClients, config.Remediation, logger)
+	plan := r.Plan(reports.EvaluateThresholds(testRunResults, config.Thresholds))
+	if len(plan.Actions) == 0 {
+		logger.Infow("No nodes require remediation")
+		return nil
+	}
+	err := remediation.WritePlan(plan, os.Stderr)
+	if err != nil {
+		return fmt.Errorf("failed to print remediation plan: %w", err)
+	}
+	if *config.Remediation.DryRun {
+		logger.Infow("Skipped remediation since dry run is enabled", "actions", len(plan.Actions))
+		return nil
 	}
+	return r.Apply(ctx, plan)
 }
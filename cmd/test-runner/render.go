@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/evaluator"
+	"go.uber.org/zap"
+)
+
+func runRender(ctx context.Context, logger *zap.SugaredLogger, args []string) int {
+	flags := flag.NewFlagSet("render", flag.ExitOnError)
+	configFile := flags.String("config", "config.yaml", "(optional) absolute path to the config file")
+	serverDryRun := flags.Bool("server-dry-run", false, "(optional) validate the printed manifests using a server-side dry run")
+	simulate := flags.String("simulate", "", "(optional) path to a scenario file for rendering against a simulated cluster")
+	overrides := config.AddOverrideFlags(flags)
+	addFlagAliases(flags, overrides, clientFlagAliases)
+	err := flags.Parse(args)
+	if err != nil {
+		logger.Errorw("failed to parse flags", "error", err)
+		return 1
+	}
+
+	config, err := config.Read(*configFile, overrides.Overrides()...)
+	if err != nil {
+		logger.Errorw("failed to read Config", "error", err)
+		return 1
+	}
+
+	clients, err := newClusterClients(logger, config, *simulate)
+	if err != nil {
+		logger.Errorw("failed to create clients", "error", err)
+		return 1
+	}
+	return renderManifests(ctx, logger, newTestRunner(config, logger, clients), *serverDryRun)
+}
+
+// renderManifests prints the kubernetes manifests of the test run without creating them.
+func renderManifests(ctx context.Context, logger *zap.SugaredLogger, testRunner evaluator.TestRunnerInterface,
+	serverDryRun bool) int {
+	objects, err := testRunner.RenderManifests(ctx)
+	if err != nil {
+		logger.Errorw("Failed to render manifests", "error", err)
+		return 1
+	}
+	if serverDryRun {
+		err = testRunner.ValidateManifests(ctx, objects)
+		if err != nil {
+			logger.Errorw("Manifests failed server-side validation", "error", err)
+			return 1
+		}
+	}
+	err = evaluator.WriteManifests(objects, os.Stdout)
+	if err != nil {
+		logger.Errorw("Failed to print manifests", "error", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/evaluator"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/server"
+	"go.uber.org/zap"
+)
+
+func runServe(ctx context.Context, logger *zap.SugaredLogger, args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	configFile := flags.String("config", "config.yaml", "(optional) absolute path to the config file")
+	simulate := flags.String("simulate", "", "(optional) path to a scenario file for running against a simulated cluster")
+	overrides := config.AddOverrideFlags(flags)
+	addFlagAliases(flags, overrides, clientFlagAliases, serveFlagAliases)
+	err := flags.Parse(args)
+	if err != nil {
+		logger.Errorw("failed to parse flags", "error", err)
+		return 1
+	}
+
+	config, err := config.Read(*configFile, overrides.Overrides()...)
+	if err != nil {
+		logger.Errorw("failed to read Config", "error", err)
+		return 1
+	}
+
+	clients, err := newClusterClients(logger, config, *simulate)
+	if err != nil {
+		logger.Errorw("failed to create clients", "error", err)
+		return 1
+	}
+
+	var store server.Store
+	if config.Serve.StoreDirectory != "" {
+		store, err = server.NewFileStore(config.Serve.StoreDirectory, config.Serve.HistoryLimit)
+		if err != nil {
+			logger.Errorw("failed to create run store", "error", err)
+			return 1
+		}
+	} else {
+		store = server.NewMemoryStore(config.Serve.HistoryLimit)
+	}
+
+	s := server.New(config, logger, store, func() evaluator.TestRunnerInterface {
+		return newTestRunner(config, logger, clients)
+	})
+	err = s.Serve(ctx)
+	if err != nil {
+		logger.Errorw("Failed to serve", "error", err)
+		return 1
+	}
+	return 0
+}
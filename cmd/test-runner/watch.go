@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/evaluator"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/watcher"
+	"go.uber.org/zap"
+)
+
+func runWatch(ctx context.Context, logger *zap.SugaredLogger, args []string) int {
+	flags := flag.NewFlagSet("watch", flag.ExitOnError)
+	configFile := flags.String("config", "config.yaml", "(optional) absolute path to the config file")
+	simulate := flags.String("simulate", "", "(optional) path to a scenario file for running against a simulated cluster")
+	skipPreflight := flags.Bool("skip-preflight", false, "(optional) skip the preflight checks before watching the nodes")
+	overrides := config.AddOverrideFlags(flags)
+	addFlagAliases(flags, overrides, clientFlagAliases, watchFlagAliases)
+	err := flags.Parse(args)
+	if err != nil {
+		logger.Errorw("failed to parse flags", "error", err)
+		return 1
+	}
+
+	c, err := config.Read(*configFile, overrides.Overrides()...)
+	if err != nil {
+		logger.Errorw("failed to read Config", "error", err)
+		return 1
+	}
+
+	k8sClient, httpClient, err := newClients(logger, c, *simulate)
+	if err != nil {
+		logger.Errorw("failed to create clients", "error", err)
+		return 1
+	}
+
+	if !*skipPreflight {
+		checks := evaluator.RunWatchPreflightChecks(ctx, k8sClient, c)
+		err = evaluator.WritePreflightChecks(checks, os.Stderr)
+		if err != nil {
+			logger.Errorw("Failed to print preflight checks", "error", err)
+			return 1
+		}
+		if !evaluator.PreflightChecksPassed(checks) {
+			logger.Errorw("Preflight checks failed")
+			return 1
+		}
+	}
+
+	w := watcher.New(c, logger, k8sClient, func(runConfig *config.Config) evaluator.TestRunnerInterface {
+		return evaluator.NewTestRunnerWithClients(runConfig, logger, k8sClient, httpClient)
+	})
+	err = w.Watch(ctx)
+	if err != nil {
+		logger.Errorw("Failed to watch nodes", "error", err)
+		return 1
+	}
+	return 0
+}
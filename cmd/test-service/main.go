@@ -3,28 +3,23 @@ package main
 import (
 	"fmt"
 	"log"
-	"math"
 	"net/http"
 	"os"
 	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/testservice"
 )
 
 var servicePort = os.Getenv("SERVICE_PORT")
 
 func main() {
-	serviceMux := http.NewServeMux()
-
-	serviceMux.Handle("/", http.HandlerFunc(handleUnknownPath))
-	serviceMux.Handle("/ping", http.HandlerFunc(handlePing))
-	serviceMux.Handle("/cpu-intensive-task", http.HandlerFunc(handleCPUIntensiveTask))
-
 	if servicePort == "" {
 		servicePort = "8080"
 	}
 	listenAddress := fmt.Sprintf(":%s", servicePort)
 	server := &http.Server{
 		Addr:              listenAddress,
-		Handler:           serviceMux,
+		Handler:           testservice.NewHandler(testservice.Options{}),
 		ReadHeaderTimeout: time.Second * 5,
 	}
 
@@ -34,29 +29,3 @@ func main() {
 		log.Fatalf("Failed to listen to test service: %v", err)
 	}
 }
-
-func handleUnknownPath(w http.ResponseWriter, _ *http.Request) {
-	_, err := fmt.Fprintf(w, "{\"status\":\"not_found\"}")
-	if err != nil {
-		log.Printf("Failed to write response to ping: %v", err)
-	}
-	w.WriteHeader(http.StatusNotFound)
-}
-
-func handlePing(w http.ResponseWriter, _ *http.Request) {
-	_, err := fmt.Fprintf(w, "{\"status\":\"success\"}")
-	if err != nil {
-		log.Printf("Failed to write response to ping: %v", err)
-	}
-}
-
-func handleCPUIntensiveTask(w http.ResponseWriter, _ *http.Request) {
-	var result float64
-	for i := 0; i < int(math.Pow(10, 5)); i++ {
-		result += math.Tan(float64(i)) * math.Atan(float64(i))
-	}
-	_, err := fmt.Fprintf(w, "{\"status\":\"success\",\"result\":\"%.2f\"}", result)
-	if err != nil {
-		log.Printf("Failed to write response to CPU intensive task: %v", err)
-	}
-}
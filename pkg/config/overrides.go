@@ -0,0 +1,210 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is the prefix of the environment variables overriding the config fields
+// (e.g. K8S_NODE_PERF_CLIENT_QPS overrides client.qps).
+const EnvPrefix = "K8S_NODE_PERF_"
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	stringsType  = reflect.TypeOf([]string{})
+)
+
+// Field is a config field which can be overridden, identified by the path of its YAML keys (e.g. client.qps).
+type Field struct {
+	Path    string
+	EnvName string
+	Type    reflect.Type
+	index   []int
+}
+
+// Override replaces the value of the config field at the path. The value is parsed as YAML
+// (e.g. "5m", "true" or "[a, b]") unless the field is a string.
+type Override struct {
+	Path  string
+	Value string
+}
+
+// Fields returns all the config fields which can be overridden. Nested structs are expanded into their
+// fields, while lists and maps are overridden as a whole.
+func Fields() []Field {
+	return structFields(reflect.TypeOf(Config{}), "", nil)
+}
+
+func structFields(t reflect.Type, prefix string, index []int) []Field {
+	fields := []Field{}
+	for i := range t.NumField() {
+		structField := t.Field(i)
+		key := strings.Split(structField.Tag.Get("yaml"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+		path := prefix + key
+		fieldIndex := append(slices.Clone(index), i)
+		if structField.Type.Kind() == reflect.Struct && structField.Type != durationType {
+			fields = append(fields, structFields(structField.Type, path+".", fieldIndex)...)
+			continue
+		}
+		fields = append(fields, Field{
+			Path:    path,
+			EnvName: EnvPrefix + envName(path),
+			Type:    structField.Type,
+			index:   fieldIndex,
+		})
+	}
+	return fields
+}
+
+// envName converts the path of a field to the upper snake case (e.g. testService.image becomes TEST_SERVICE_IMAGE).
+func envName(path string) string {
+	name := strings.Builder{}
+	var previous rune
+	for _, r := range path {
+		switch {
+		case r == '.':
+			name.WriteRune('_')
+		case unicode.IsUpper(r) && (unicode.IsLower(previous) || unicode.IsDigit(previous)):
+			name.WriteRune('_')
+			name.WriteRune(r)
+		default:
+			name.WriteRune(unicode.ToUpper(r))
+		}
+		previous = r
+	}
+	return name.String()
+}
+
+// EnvOverrides returns the overrides provided as environment variables in the environment.
+func EnvOverrides(environ []string) []Override {
+	values := map[string]string{}
+	for _, env := range environ {
+		name, value, ok := strings.Cut(env, "=")
+		if ok && strings.HasPrefix(name, EnvPrefix) {
+			values[name] = value
+		}
+	}
+	overrides := []Override{}
+	for _, field := range Fields() {
+		if value, ok := values[field.EnvName]; ok {
+			overrides = append(overrides, Override{Path: field.Path, Value: value})
+		}
+	}
+	return overrides
+}
+
+// Apply sets the fields of the config to the values of the overrides in order.
+func Apply(config *Config, overrides []Override) error {
+	fields := map[string]Field{}
+	for _, field := range Fields() {
+		fields[field.Path] = field
+	}
+	for _, override := range overrides {
+		field, ok := fields[override.Path]
+		if !ok {
+			return fmt.Errorf("unknown config field %s", override.Path)
+		}
+		err := setField(reflect.ValueOf(config).Elem().FieldByIndex(field.index), override.Value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q for config field %s: %w", override.Value, override.Path, err)
+		}
+	}
+	return nil
+}
+
+func setField(field reflect.Value, value string) error {
+	switch {
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+		return nil
+	case field.Kind() == reflect.Pointer && field.Type().Elem().Kind() == reflect.String:
+		field.Set(reflect.ValueOf(&value))
+		return nil
+	}
+	// The field is replaced instead of merging the value into the existing lists and maps
+	parsed := reflect.New(field.Type())
+	err := yaml.Unmarshal([]byte(value), parsed.Interface())
+	if err != nil {
+		return err
+	}
+	field.Set(parsed.Elem())
+	return nil
+}
+
+// FlagOverrides collects the overrides of the config fields provided as flags, in the order of the flags.
+type FlagOverrides struct {
+	overrides []Override
+}
+
+// AddOverrideFlags adds a flag for each of the config fields to the flag set, named after the path of the field.
+func AddOverrideFlags(flags *flag.FlagSet) *FlagOverrides {
+	flagOverrides := &FlagOverrides{}
+	for _, field := range Fields() {
+		flags.Var(&overrideFlag{field: field, flagOverrides: flagOverrides}, field.Path,
+			fmt.Sprintf("(optional) override %s (or set %s)", field.Path, field.EnvName))
+	}
+	return flagOverrides
+}
+
+// AddAlias adds a shorthand flag which overrides the config field at the path in the same way as the flag named
+// after the path. Lists of strings are provided to the alias comma separated (e.g. -as-group a,b).
+func (o *FlagOverrides) AddAlias(flags *flag.FlagSet, name string, path string, usage string) {
+	for _, field := range Fields() {
+		if field.Path == path {
+			flags.Var(&overrideFlag{field: field, flagOverrides: o, commaSeparated: field.Type == stringsType}, name, usage)
+			return
+		}
+	}
+	panic(fmt.Sprintf("alias %s of unknown config field %s", name, path))
+}
+
+// Overrides returns the overrides provided as flags.
+func (o *FlagOverrides) Overrides() []Override {
+	return o.overrides
+}
+
+type overrideFlag struct {
+	field          Field
+	flagOverrides  *FlagOverrides
+	commaSeparated bool
+}
+
+var _ flag.Value = (*overrideFlag)(nil)
+
+func (f *overrideFlag) String() string {
+	return ""
+}
+
+func (f *overrideFlag) Set(value string) error {
+	if f.commaSeparated {
+		list, err := json.Marshal(strings.Split(value, ","))
+		if err != nil {
+			return err
+		}
+		value = string(list)
+	}
+	// The value is validated when the flags are parsed instead of when the config is read
+	err := setField(reflect.New(f.field.Type).Elem(), value)
+	if err != nil {
+		return err
+	}
+	f.flagOverrides.overrides = append(f.flagOverrides.overrides, Override{Path: f.field.Path, Value: value})
+	return nil
+}
+
+// IsBoolFlag allows the boolean fields to be enabled without a value (e.g. -remediation.enabled).
+func (f *overrideFlag) IsBoolFlag() bool {
+	return f.field.Type.Kind() == reflect.Bool ||
+		(f.field.Type.Kind() == reflect.Pointer && f.field.Type.Elem().Kind() == reflect.Bool)
+}
@@ -0,0 +1,210 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFields(t *testing.T) {
+	envNames := map[string]string{}
+	for _, field := range Fields() {
+		envNames[field.Path] = field.EnvName
+	}
+	expected := map[string]string{
+		"kubeConfig": "K8S_NODE_PERF_KUBE_CONFIG",
+		"client.qps": "K8S_NODE_PERF_CLIENT_QPS",
+		"testService.scheduling.runtimeClassName":   "K8S_NODE_PERF_TEST_SERVICE_SCHEDULING_RUNTIME_CLASS_NAME",
+		"ingress.tlsSecretName":                     "K8S_NODE_PERF_INGRESS_TLS_SECRET_NAME",
+		"history.regression.minBaselineRuns":        "K8S_NODE_PERF_HISTORY_REGRESSION_MIN_BASELINE_RUNS",
+		"thresholds.suites":                         "K8S_NODE_PERF_THRESHOLDS_SUITES",
+		"testService.podTemplate":                   "K8S_NODE_PERF_TEST_SERVICE_POD_TEMPLATE",
+		"remediation.enabled":                       "K8S_NODE_PERF_REMEDIATION_ENABLED",
+		"history.degradation.maxIncreasePercentage": "K8S_NODE_PERF_HISTORY_DEGRADATION_MAX_INCREASE_PERCENTAGE",
+	}
+	for path, envName := range expected {
+		if envNames[path] != envName {
+			t.Errorf("expected field %s with environment variable %s, but got %q", path, envName, envNames[path])
+		}
+	}
+}
+
+func TestReadWithOverrides(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(configFile, []byte(`namespace: "from-file"
+client:
+  qps: 10
+report:
+  columns: ["zone"]
+timeouts:
+  ingressReady: "3m"
+`), 0600)
+	if err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	t.Setenv("K8S_NODE_PERF_NAMESPACE", "from-env")
+	t.Setenv("K8S_NODE_PERF_CLIENT_QPS", "20")
+	t.Setenv("K8S_NODE_PERF_REMEDIATION_ENABLED", "true")
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flagOverrides := AddOverrideFlags(flags)
+	err = flags.Parse([]string{"-client.qps", "30", "-report.columns", "[instanceType, kernelVersion]",
+		"-remediation.dryRun=false", "-testService.scheduling.runtimeClassName", "gvisor"})
+	if err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+
+	config, err := Read(configFile, flagOverrides.Overrides()...)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	if config.Namespace != "from-env" {
+		t.Errorf("expected the environment variable to override the file, but got namespace %s", config.Namespace)
+	}
+	if config.Client.QPS != 30 {
+		t.Errorf("expected the flag to override the environment variable, but got qps %v", config.Client.QPS)
+	}
+	if !slices.Equal(config.Report.Columns, []string{"instanceType", "kernelVersion"}) {
+		t.Errorf("expected the columns to be replaced, but got %v", config.Report.Columns)
+	}
+	if !config.Remediation.Enabled || *config.Remediation.DryRun {
+		t.Errorf("expected remediation to be enabled without a dry run, but got %+v", config.Remediation)
+	}
+	if config.TestService.Scheduling.RuntimeClassName == nil || *config.TestService.Scheduling.RuntimeClassName != "gvisor" {
+		t.Errorf("expected the runtime class name to be set, but got %v", config.TestService.Scheduling.RuntimeClassName)
+	}
+	if config.Timeouts.IngressReady != 3*time.Minute || config.Timeouts.DeploymentReady != time.Minute {
+		t.Errorf("expected the file and the defaults to be kept, but got %+v", config.Timeouts)
+	}
+
+	err = flags.Parse([]string{"-client.burst", "many"})
+	if err == nil {
+		t.Errorf("expected an error for an invalid flag value")
+	}
+	t.Setenv("K8S_NODE_PERF_TIMEOUTS_DEPLOYMENT_READY", "soon")
+	_, err = Read(configFile)
+	if err == nil {
+		t.Errorf("expected an error for an invalid environment variable value")
+	}
+}
+
+func TestAddAlias(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flagOverrides := AddOverrideFlags(flags)
+	flagOverrides.AddAlias(flags, "remediate", "remediation.enabled", "")
+	flagOverrides.AddAlias(flags, "remediation-dry-run", "remediation.dryRun", "")
+	flagOverrides.AddAlias(flags, "as-group", "client.impersonate.groups", "")
+	flagOverrides.AddAlias(flags, "qps", "client.qps", "")
+	err := flags.Parse([]string{"-remediate", "-remediation-dry-run=false", "-as-group", "system:masters,devs",
+		"-qps", "10", "-client.qps", "20"})
+	if err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+
+	config, err := Parse([]byte("{}"))
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	err = Apply(config, flagOverrides.Overrides())
+	if err != nil {
+		t.Fatalf("failed to apply overrides: %v", err)
+	}
+	if !config.Remediation.Enabled || *config.Remediation.DryRun {
+		t.Errorf("expected remediation to be enabled without a dry run, but got %+v", config.Remediation)
+	}
+	if !slices.Equal(config.Client.Impersonate.Groups, []string{"system:masters", "devs"}) {
+		t.Errorf("expected the comma separated groups to be split, but got %v", config.Client.Impersonate.Groups)
+	}
+	if config.Client.QPS != 20 {
+		t.Errorf("expected the aliases to take effect in the order of the flags, but got qps %v", config.Client.QPS)
+	}
+
+	err = flags.Parse([]string{"-qps", "many"})
+	if err == nil {
+		t.Errorf("expected an error for an invalid alias value")
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	defaults, err := Parse([]byte("{}"))
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	if !*defaults.Remediation.DryRun || defaults.Remediation.Annotate || defaults.Thresholds.FailOnProvisioningFailure {
+		t.Errorf("expected only the remediation dry run to be enabled by default, but got %+v", defaults)
+	}
+
+	// The example config documents the defaults, apart from the values which are required and the optional
+	// behaviours which the example enables
+	example, err := Read(filepath.Join("..", "..", "config.yaml"))
+	if err != nil {
+		t.Fatalf("failed to read example config: %v", err)
+	}
+	defaults.Namespace = example.Namespace
+	defaults.TestService.Image = example.TestService.Image
+	defaults.Ingress = example.Ingress
+	defaults.Thresholds.FailOnProvisioningFailure = example.Thresholds.FailOnProvisioningFailure
+	defaults.Remediation.Annotate = example.Remediation.Annotate
+	expected := &bytes.Buffer{}
+	err = Write(defaults, expected)
+	if err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	actual := &bytes.Buffer{}
+	err = Write(example, actual)
+	if err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if actual.String() != expected.String() {
+		t.Errorf("expected the example config to match the defaults, but got:\n%s\ninstead of:\n%s", actual, expected)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	config, err := Parse([]byte(`timeouts:
+  ingressReady: "90s"
+`))
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	output := &bytes.Buffer{}
+	err = Write(config, output)
+	if err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if !bytes.Contains(output.Bytes(), []byte("ingressReady: 1m30s")) {
+		t.Errorf("expected the durations to be written as strings, but got:\n%s", output.String())
+	}
+
+	// The printed config can be read again
+	printed, err := Parse(output.Bytes())
+	if err != nil {
+		t.Fatalf("failed to parse printed config: %v", err)
+	}
+	if printed.Timeouts.IngressReady != 90*time.Second || printed.Client.QPS != config.Client.QPS {
+		t.Errorf("expected the printed config to match, but got %+v", printed)
+	}
+}
+
+func TestReadDefaults(t *testing.T) {
+	t.Setenv("K8S_NODE_PERF_CLIENT_QPS", "20")
+	t.Setenv("K8S_NODE_PERF_CLIENT_CONTEXT", "staging")
+	config, err := ReadDefaults(Override{Path: "client.context", Value: "production"})
+	if err != nil {
+		t.Fatalf("failed to read defaults: %v", err)
+	}
+	if config.Client.QPS != 20 {
+		t.Errorf("expected the environment variable to be applied, but got %v", config.Client.QPS)
+	}
+	if config.Client.Context != "production" {
+		t.Errorf("expected the override to take precedence over the environment variable, but got %s",
+			config.Client.Context)
+	}
+	if config.Timeouts.IngressReady != time.Minute {
+		t.Errorf("expected the defaults to be filled in, but got %v", config.Timeouts.IngressReady)
+	}
+}
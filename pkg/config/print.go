@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Write writes the config as YAML in the layout of the config file, with the durations written as strings.
+func Write(config *Config, output io.Writer) error {
+	node, err := toNode(reflect.ValueOf(*config))
+	if err != nil {
+		return fmt.Errorf("failed to convert config to YAML: %w", err)
+	}
+	encoder := yaml.NewEncoder(output)
+	encoder.SetIndent(2)
+	err = encoder.Encode(node)
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return encoder.Close()
+}
+
+func toNode(value reflect.Value) (*yaml.Node, error) {
+	switch {
+	case value.Type() == durationType:
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: time.Duration(value.Int()).String()}, nil
+	case value.Kind() == reflect.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for i := range value.NumField() {
+			key := strings.Split(value.Type().Field(i).Tag.Get("yaml"), ",")[0]
+			if key == "" || key == "-" {
+				continue
+			}
+			fieldNode, err := toNode(value.Field(i))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, fieldNode)
+		}
+		return node, nil
+	case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Struct:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for i := range value.Len() {
+			itemNode, err := toNode(value.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, itemNode)
+		}
+		return node, nil
+	}
+	node := &yaml.Node{}
+	err := node.Encode(value.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
@@ -4,26 +4,101 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"gopkg.in/yaml.v3"
-	"k8s.io/client-go/util/homedir"
 )
 
 type Config struct {
-	KubeConfig   string      `yaml:"kubeConfig"`
-	Namespace    string      `yaml:"namespace"`
-	TestService  TestService `yaml:"testService"`
-	NodeSelector Selector    `yaml:"nodeSelector"`
-	Ingress      Ingress     `yaml:"ingress"`
+	// KubeConfig is a list of kubeconfig files merged in the same way as the KUBECONFIG environment variable
+	// (defaults to the KUBECONFIG environment variable or ~/.kube/config)
+	KubeConfig string `yaml:"kubeConfig"`
+	Client     Client `yaml:"client"`
+	// Clusters are evaluated concurrently instead of the current context of the kubeconfig when provided
+	Clusters  []Cluster `yaml:"clusters"`
+	Namespace string    `yaml:"namespace"`
+	// UniqueNamespacePerRun suffixes the namespace with the run ID so that concurrent runs do not collide
+	UniqueNamespacePerRun bool `yaml:"uniqueNamespacePerRun"`
+	// ForceNamespaceDeletion allows deleting an existing namespace which was not created by the evaluator
+	ForceNamespaceDeletion bool          `yaml:"forceNamespaceDeletion"`
+	TestService            TestService   `yaml:"testService"`
+	NodeSelector           Selector      `yaml:"nodeSelector"`
+	Ingress                Ingress       `yaml:"ingress"`
+	Timeouts               Timeouts      `yaml:"timeouts"`
+	Report                 Report        `yaml:"report"`
+	ResourceUsage          ResourceUsage `yaml:"resourceUsage"`
+	Thresholds             Thresholds    `yaml:"thresholds"`
+	Remediation            Remediation   `yaml:"remediation"`
+	Serve                  Serve         `yaml:"serve"`
+	Watch                  Watch         `yaml:"watch"`
+	History                History       `yaml:"history"`
+}
+
+type Cluster struct {
+	// Name identifies the cluster in the reports (defaults to the context)
+	Name string `yaml:"name"`
+	// KubeConfig defaults to the kubeconfig of the config
+	KubeConfig string `yaml:"kubeConfig"`
+	// Context of the kubeconfig to use (defaults to the context of the client)
+	Context string `yaml:"context"`
+}
+
+// Client configures how the kubernetes clients connect to the API servers.
+type Client struct {
+	// Context of the kubeconfig to use (defaults to the current context)
+	Context     string      `yaml:"context"`
+	Impersonate Impersonate `yaml:"impersonate"`
+	// QPS and Burst limit the requests made to the API server
+	QPS   float32 `yaml:"qps"`
+	Burst int     `yaml:"burst"`
+	// Timeout of a single request made to the API server (no timeout if zero)
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+type Impersonate struct {
+	User   string   `yaml:"user"`
+	Groups []string `yaml:"groups"`
 }
 
 type TestService struct {
-	Image string `yaml:"image"`
+	Image      string     `yaml:"image"`
+	Scheduling Scheduling `yaml:"scheduling"`
+
+	// PodTemplate is a strategic merge patch applied on top of the generated pod template
+	PodTemplate   map[string]interface{} `yaml:"podTemplate"`
+	NodeOverrides []NodeOverride         `yaml:"nodeOverrides"`
+}
+
+// NodeOverride applies an additional pod template patch to the test services of the nodes
+// matching the label selector. Overrides are applied in order after the common pod template.
+type NodeOverride struct {
+	LabelSelector string                 `yaml:"labelSelector"`
+	PodTemplate   map[string]interface{} `yaml:"podTemplate"`
+}
+
+type Scheduling struct {
+	// UseNodeAffinity lets the scheduler place the test pods using a required node affinity
+	// on the node's hostname instead of binding them directly to the node
+	UseNodeAffinity   bool         `yaml:"useNodeAffinity"`
+	TolerateAll       bool         `yaml:"tolerateAll"`
+	Tolerations       []Toleration `yaml:"tolerations"`
+	PriorityClassName string       `yaml:"priorityClassName"`
+	RuntimeClassName  *string      `yaml:"runtimeClassName"`
+}
+
+type Toleration struct {
+	Key               string `yaml:"key"`
+	Operator          string `yaml:"operator"`
+	Value             string `yaml:"value"`
+	Effect            string `yaml:"effect"`
+	TolerationSeconds *int64 `yaml:"tolerationSeconds"`
 }
 
 type Selector struct {
 	LabelSelector string `yaml:"labelSelector"`
 	FieldSelector string `yaml:"fieldSelector"`
+	// Names restricts the evaluation to the nodes with the names among the nodes matching the selectors
+	Names []string `yaml:"names"`
 }
 
 type Ingress struct {
@@ -35,7 +110,120 @@ type Ingress struct {
 	Annotations     map[string]string `yaml:"annotations"`
 }
 
-func Read(c string) (*Config, error) {
+type Report struct {
+	// Columns are the node fields (e.g. instanceType, zone, kernelVersion) or node labels added to the report
+	Columns []string `yaml:"columns"`
+	// GroupBy is the node field or node label by which the results are additionally aggregated
+	GroupBy string `yaml:"groupBy"`
+	// Format of the report (text or json, defaults to the TEST_RUNNER_REPORT_FORMAT environment variable or text)
+	Format string `yaml:"format"`
+	// File the report is appended to in addition to the standard output (defaults to the TEST_RUNNER_REPORT_FILE
+	// environment variable)
+	File string `yaml:"file"`
+}
+
+// ResourceUsage configures the collection of the test pods' resource usage from the kubelets
+type ResourceUsage struct {
+	Disabled bool `yaml:"disabled"`
+	// SampleInterval is the interval at which the kubelet is sampled while a test is running
+	SampleInterval time.Duration `yaml:"sampleInterval"`
+}
+
+// Thresholds decide whether a node passed the evaluation
+type Thresholds struct {
+	FailOnProvisioningFailure bool             `yaml:"failOnProvisioningFailure"`
+	Suites                    []SuiteThreshold `yaml:"suites"`
+}
+
+// SuiteThreshold applies to the test suite with the name, or to all the test suites if the name is empty
+type SuiteThreshold struct {
+	Suite               string        `yaml:"suite"`
+	MaxAverageLatency   time.Duration `yaml:"maxAverageLatency"`
+	MaxFailedPercentage *float64      `yaml:"maxFailedPercentage"`
+}
+
+// Remediation configures the actions taken on the nodes which failed the thresholds
+type Remediation struct {
+	Enabled bool `yaml:"enabled"`
+	// DryRun only prints the actions which would be taken (defaults to true)
+	DryRun *bool `yaml:"dryRun"`
+	// MaxNodes is the maximum number of nodes actioned in a single run
+	MaxNodes int               `yaml:"maxNodes"`
+	Labels   map[string]string `yaml:"labels"`
+	Taints   []Taint           `yaml:"taints"`
+	Cordon   bool              `yaml:"cordon"`
+	// Annotate adds the evaluation summary of the node as an annotation
+	Annotate bool `yaml:"annotate"`
+}
+
+type Taint struct {
+	Key    string `yaml:"key"`
+	Value  string `yaml:"value"`
+	Effect string `yaml:"effect"`
+}
+
+// Serve configures the continuous evaluation daemon
+type Serve struct {
+	ListenAddress string        `yaml:"listenAddress"`
+	Interval      time.Duration `yaml:"interval"`
+	// StoreDirectory persists the runs as files in the directory, instead of only keeping them in memory
+	StoreDirectory string `yaml:"storeDirectory"`
+	// HistoryLimit is the maximum number of runs retained
+	HistoryLimit int `yaml:"historyLimit"`
+}
+
+// Watch configures the evaluation of the nodes joining the cluster
+type Watch struct {
+	// TaintUnevaluated taints the new nodes until they pass the evaluation
+	TaintUnevaluated bool `yaml:"taintUnevaluated"`
+	// BatchDelay is the time to wait for more nodes to become ready before evaluating the ready nodes together
+	BatchDelay time.Duration `yaml:"batchDelay"`
+}
+
+// History configures the persistent store of the results of the runs
+type History struct {
+	// Path of the database file, the results are not stored if empty
+	Path string `yaml:"path"`
+	// Cluster is the name the results are stored under (defaults to the current context of the kubeconfig)
+	Cluster     string      `yaml:"cluster"`
+	Degradation Degradation `yaml:"degradation"`
+	Regression  Regression  `yaml:"regression"`
+}
+
+// Degradation decides whether the performance of a node is gradually degrading across the runs
+type Degradation struct {
+	// Window is the number of the latest runs in which the trend is checked
+	Window int `yaml:"window"`
+	// MinRuns is the minimum number of runs required for checking the trend
+	MinRuns int `yaml:"minRuns"`
+	// MaxIncreasePercentage is the maximum increase of the average latency allowed across the window
+	MaxIncreasePercentage float64 `yaml:"maxIncreasePercentage"`
+}
+
+// Regression decides whether the latest results of a node got worse than the baseline of its previous results
+type Regression struct {
+	// BaselineRuns is the number of runs preceding the latest run which form the baseline
+	BaselineRuns int `yaml:"baselineRuns"`
+	// MinBaselineRuns is the minimum number of runs of the node required for comparing with the baseline
+	MinBaselineRuns int `yaml:"minBaselineRuns"`
+	// MaxDeviation is the maximum number of standard deviations of the baseline by which a metric can increase
+	MaxDeviation float64 `yaml:"maxDeviation"`
+	// MinLatencyIncreasePercentage is the minimum increase of the average latency reported as a regression
+	MinLatencyIncreasePercentage float64 `yaml:"minLatencyIncreasePercentage"`
+	// MinFailedPercentageIncrease is the minimum increase in percentage points of the failed requests
+	// reported as a regression
+	MinFailedPercentageIncrease float64 `yaml:"minFailedPercentageIncrease"`
+}
+
+type Timeouts struct {
+	NamespaceDeletion time.Duration `yaml:"namespaceDeletion"`
+	DeploymentReady   time.Duration `yaml:"deploymentReady"`
+	IngressReady      time.Duration `yaml:"ingressReady"`
+}
+
+// Read reads the config file and applies the overrides provided as environment variables, followed by the
+// provided overrides, before filling in the defaults.
+func Read(c string, overrides ...Override) (*Config, error) {
 	configFile, err := filepath.Abs(c)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve the absolute path of config file: %w", err)
@@ -46,18 +234,140 @@ func Read(c string) (*Config, error) {
 	}
 	configContent = []byte(os.ExpandEnv(string(configContent)))
 
-	config := &Config{}
-	err = yaml.Unmarshal(configContent, config)
+	config, err := parse(configContent, append(EnvOverrides(os.Environ()), overrides...))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse config file content: %w", err)
 	}
+	return config, nil
+}
+
+// ReadDefaults applies the overrides provided as environment variables, followed by the provided overrides,
+// to the defaults when there is no config file.
+func ReadDefaults(overrides ...Override) (*Config, error) {
+	config, err := parse(nil, append(EnvOverrides(os.Environ()), overrides...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply the overrides to the defaults: %w", err)
+	}
+	return config, nil
+}
+
+// Parse reads the config from YAML (or JSON) content and fills in the defaults.
+func Parse(content []byte) (*Config, error) {
+	return parse(content, nil)
+}
+
+func parse(content []byte, overrides []Override) (*Config, error) {
+	config := &Config{}
+	err := yaml.Unmarshal(content, config)
+	if err != nil {
+		return nil, err
+	}
+	err = Apply(config, overrides)
+	if err != nil {
+		return nil, err
+	}
 	mergeDefaults(config)
+	err = validateClusters(config.Clusters)
+	if err != nil {
+		return nil, err
+	}
 	return config, nil
 }
 
+// validateClusters checks that the clusters can be told apart in the reports.
+func validateClusters(clusters []Cluster) error {
+	names := map[string]struct{}{}
+	for i, cluster := range clusters {
+		if cluster.Name == "" {
+			return fmt.Errorf("name or context of cluster %d is required", i)
+		}
+		if _, ok := names[cluster.Name]; ok {
+			return fmt.Errorf("cluster %s is provided more than once", cluster.Name)
+		}
+		names[cluster.Name] = struct{}{}
+	}
+	return nil
+}
+
 func mergeDefaults(config *Config) {
-	home := homedir.HomeDir()
-	if config.KubeConfig == "" {
-		config.KubeConfig = filepath.Join(home, ".kube", "config")
+	if config.Client.QPS == 0 {
+		config.Client.QPS = 50
+	}
+	if config.Client.Burst == 0 {
+		config.Client.Burst = 100
+	}
+	for i := range config.Clusters {
+		if config.Clusters[i].Context == "" {
+			config.Clusters[i].Context = config.Client.Context
+		}
+		if config.Clusters[i].Name == "" {
+			config.Clusters[i].Name = config.Clusters[i].Context
+		}
+		if config.Clusters[i].KubeConfig == "" {
+			config.Clusters[i].KubeConfig = config.KubeConfig
+		}
+	}
+	if len(config.Clusters) > 0 && config.Report.GroupBy == "" {
+		// Comparing the same instance types across the clusters is the main use of evaluating them together
+		config.Report.GroupBy = "instanceType"
 	}
+	if config.Timeouts.NamespaceDeletion == 0 {
+		config.Timeouts.NamespaceDeletion = 5 * time.Minute
+	}
+	if config.Timeouts.DeploymentReady == 0 {
+		config.Timeouts.DeploymentReady = time.Minute
+	}
+	if config.Timeouts.IngressReady == 0 {
+		config.Timeouts.IngressReady = time.Minute
+	}
+	// Nodes are only actioned when the dry run is disabled explicitly
+	if config.Remediation.DryRun == nil {
+		config.Remediation.DryRun = boolPtr(true)
+	}
+	if config.Remediation.MaxNodes == 0 {
+		config.Remediation.MaxNodes = 1
+	}
+	if config.Serve.ListenAddress == "" {
+		config.Serve.ListenAddress = ":8080"
+	}
+	if config.Serve.Interval == 0 {
+		config.Serve.Interval = time.Hour
+	}
+	if config.Serve.HistoryLimit == 0 {
+		config.Serve.HistoryLimit = 100
+	}
+	if config.Watch.BatchDelay == 0 {
+		config.Watch.BatchDelay = 30 * time.Second
+	}
+	if config.History.Degradation.Window == 0 {
+		config.History.Degradation.Window = 10
+	}
+	if config.History.Degradation.MinRuns == 0 {
+		config.History.Degradation.MinRuns = 5
+	}
+	if config.History.Degradation.MaxIncreasePercentage == 0 {
+		config.History.Degradation.MaxIncreasePercentage = 20
+	}
+	if config.History.Regression.BaselineRuns == 0 {
+		config.History.Regression.BaselineRuns = 10
+	}
+	if config.History.Regression.MinBaselineRuns == 0 {
+		config.History.Regression.MinBaselineRuns = 3
+	}
+	if config.History.Regression.MaxDeviation == 0 {
+		config.History.Regression.MaxDeviation = 3
+	}
+	if config.History.Regression.MinLatencyIncreasePercentage == 0 {
+		config.History.Regression.MinLatencyIncreasePercentage = 10
+	}
+	if config.History.Regression.MinFailedPercentageIncrease == 0 {
+		config.History.Regression.MinFailedPercentageIncrease = 1
+	}
+	if config.ResourceUsage.SampleInterval == 0 {
+		config.ResourceUsage.SampleInterval = 5 * time.Second
+	}
+}
+
+func boolPtr(value bool) *bool {
+	return &value
 }
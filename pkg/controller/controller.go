@@ -0,0 +1,276 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/evaluator"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/reports"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	namespacePrefix = "node-perf-"
+	resyncPeriod    = 10 * time.Minute
+)
+
+// specFields are the fields of the config file which can be set in the spec. The namespace and the credentials
+// are decided by the controller, and the rest of the fields only apply to the other subcommands.
+var specFields = []string{"testService", "nodeSelector", "ingress", "timeouts", "resourceUsage", "thresholds"}
+
+// RunnerFactory creates the test runner for the config read from the spec of a NodePerfEvaluation.
+type RunnerFactory func(config *config.Config) evaluator.TestRunnerInterface
+
+// Controller runs an evaluation for each generation of the NodePerfEvaluations in the cluster and
+// records the results in their status. Evaluations are run one at a time since concurrent
+// evaluations of the same nodes would affect each other's results.
+type Controller struct {
+	dynamicClient dynamic.Interface
+	runnerFactory RunnerFactory
+	logger        *zap.SugaredLogger
+	now           func() time.Time
+
+	queue workqueue.TypedRateLimitingInterface[string]
+
+	runsLock   sync.Mutex
+	cancelRuns map[string]context.CancelFunc
+}
+
+func New(dynamicClient dynamic.Interface, runnerFactory RunnerFactory, logger *zap.SugaredLogger) *Controller {
+	return &Controller{
+		dynamicClient: dynamicClient,
+		runnerFactory: runnerFactory,
+		logger:        logger,
+		now:           time.Now,
+		queue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			workqueue.DefaultTypedControllerRateLimiter[string](),
+			workqueue.TypedRateLimitingQueueConfig[string]{Name: Resource},
+		),
+		cancelRuns: map[string]context.CancelFunc{},
+	}
+}
+
+// Run watches the NodePerfEvaluations and reconciles them until the context is cancelled.
+func (c *Controller) Run(ctx context.Context) error {
+	defer c.queue.ShutDown()
+
+	informerFactory := dynamicinformer.NewDynamicSharedInformerFactory(c.dynamicClient, resyncPeriod)
+	informer := informerFactory.ForResource(GroupVersionResource).Informer()
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueue,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.enqueue(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+			if err != nil {
+				c.logger.Warnw("failed to resolve deleted evaluation", "error", err)
+				return
+			}
+			c.cancelRun(key)
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to watch %s: %w", Resource, err)
+	}
+	informerFactory.Start(ctx.Done())
+	defer informerFactory.Shutdown()
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return fmt.Errorf("failed to sync %s: %w", Resource, ctx.Err())
+	}
+	c.logger.Infow("watching node performance evaluations")
+
+	go func() {
+		<-ctx.Done()
+		c.queue.ShutDown()
+	}()
+	for c.processNextItem(ctx) {
+	}
+	return nil
+}
+
+func (c *Controller) enqueue(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		c.logger.Warnw("failed to resolve evaluation", "error", err)
+		return
+	}
+	c.queue.Add(key)
+}
+
+func (c *Controller) processNextItem(ctx context.Context) bool {
+	name, shutdown := c.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.queue.Done(name)
+
+	err := c.Reconcile(ctx, name)
+	if err != nil && ctx.Err() == nil {
+		c.logger.Errorw("failed to reconcile evaluation", "evaluation", name, "error", err)
+		c.queue.AddRateLimited(name)
+		return true
+	}
+	c.queue.Forget(name)
+	return true
+}
+
+func (c *Controller) cancelRun(name string) {
+	c.runsLock.Lock()
+	defer c.runsLock.Unlock()
+	if cancel, ok := c.cancelRuns[name]; ok {
+		c.logger.Infow("cancelling evaluation since it was deleted", "evaluation", name)
+		cancel()
+	}
+}
+
+// Reconcile runs the evaluation of the current generation of a NodePerfEvaluation unless it was already
+// completed, and writes the results into the status.
+func (c *Controller) Reconcile(ctx context.Context, name string) error {
+	client := c.dynamicClient.Resource(GroupVersionResource)
+	object, err := client.Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get evaluation %s: %w", name, err)
+	}
+	status, err := readStatus(object)
+	if err != nil {
+		return err
+	}
+	if status.ObservedGeneration == object.GetGeneration() && status.Phase != PhaseRunning && status.Phase != "" {
+		return nil
+	}
+
+	startTime := metav1.NewTime(c.now())
+	status = &NodePerfEvaluationStatus{
+		ObservedGeneration: object.GetGeneration(),
+		StartTime:          &startTime,
+	}
+	evaluationConfig, err := readSpec(object)
+	if err != nil {
+		status.Phase = PhaseFailed
+		status.Message = err.Error()
+		return c.updateStatus(ctx, name, status)
+	}
+	status.Phase = PhaseRunning
+	err = c.updateStatus(ctx, name, status)
+	if err != nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	c.runsLock.Lock()
+	c.cancelRuns[name] = cancel
+	c.runsLock.Unlock()
+	defer func() {
+		c.runsLock.Lock()
+		delete(c.cancelRuns, name)
+		c.runsLock.Unlock()
+		cancel()
+	}()
+
+	c.logger.Infow("starting evaluation", "evaluation", name, "generation", object.GetGeneration())
+	testSuites, runErr := c.runnerFactory(evaluationConfig).RunTest(runCtx)
+	if ctx.Err() != nil {
+		// The evaluation is run again for the same generation once the controller restarts
+		return ctx.Err()
+	}
+	if runCtx.Err() != nil {
+		c.logger.Infow("evaluation was cancelled", "evaluation", name)
+		return nil
+	}
+
+	testSuiteResults := reports.CalculateTestSuiteResults(testSuites)
+	evaluations := reports.EvaluateThresholds(testSuiteResults, evaluationConfig.Thresholds)
+	completionTime := metav1.NewTime(c.now())
+	status.CompletionTime = &completionTime
+	status.Nodes = newNodeStatuses(testSuiteResults, evaluations)
+	for _, node := range status.Nodes {
+		if node.Passed {
+			status.PassedNodes++
+		} else {
+			status.FailedNodes++
+		}
+	}
+	if runErr != nil {
+		status.Phase = PhaseFailed
+		status.Message = runErr.Error()
+		c.logger.Errorw("evaluation failed", "evaluation", name, "error", runErr)
+	} else {
+		status.Phase = PhaseSucceeded
+		status.Message = fmt.Sprintf("%d of %d nodes passed", status.PassedNodes, len(status.Nodes))
+		c.logger.Infow("completed evaluation", "evaluation", name, "passedNodes", status.PassedNodes,
+			"failedNodes", status.FailedNodes)
+	}
+	return c.updateStatus(ctx, name, status)
+}
+
+// readSpec reads the config of the evaluation from the spec. The spec has the same fields as the config file,
+// restricted to the spec fields.
+func readSpec(object *unstructured.Unstructured) (*config.Config, error) {
+	spec, ok := object.Object["spec"].(map[string]interface{})
+	if !ok {
+		spec = map[string]interface{}{}
+	}
+	unsupportedFields := []string{}
+	for field := range spec {
+		if !slices.Contains(specFields, field) {
+			unsupportedFields = append(unsupportedFields, field)
+		}
+	}
+	if len(unsupportedFields) > 0 {
+		slices.Sort(unsupportedFields)
+		return nil, fmt.Errorf("unsupported fields in spec: %v", unsupportedFields)
+	}
+	specContent, err := yaml.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize spec: %w", err)
+	}
+	evaluationConfig, err := config.Parse(specContent)
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec: %w", err)
+	}
+	if evaluationConfig.TestService.Image == "" {
+		return nil, fmt.Errorf("testService.image is required in spec")
+	}
+	evaluationConfig.Namespace = namespacePrefix + object.GetName()
+	return evaluationConfig, nil
+}
+
+func (c *Controller) updateStatus(ctx context.Context, name string, status *NodePerfEvaluationStatus) error {
+	client := c.dynamicClient.Resource(GroupVersionResource)
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		object, err := client.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		err = writeStatus(object, status)
+		if err != nil {
+			return err
+		}
+		_, err = client.UpdateStatus(ctx, object, metav1.UpdateOptions{})
+		return err
+	})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update status of evaluation %s: %w", name, err)
+	}
+	return nil
+}
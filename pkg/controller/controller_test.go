@@ -0,0 +1,250 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/evaluator"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"sigs.k8s.io/yaml"
+)
+
+type stubRunner struct {
+	config *config.Config
+}
+
+var _ evaluator.TestRunnerInterface = (*stubRunner)(nil)
+
+func (r *stubRunner) RunTest(ctx context.Context) ([]*evaluator.TestSuite, error) {
+	return []*evaluator.TestSuite{
+		{
+			Name: "Ping Test",
+			Tests: []*evaluator.Test{
+				{
+					NodeName:           "node-a",
+					TotalRequestsCount: 10,
+					TotalLatency:       time.Second,
+				},
+				{
+					NodeName:           "node-b",
+					TotalRequestsCount: 10,
+					TotalLatency:       30 * time.Second,
+				},
+			},
+		},
+	}, nil
+}
+
+func (r *stubRunner) RenderManifests(ctx context.Context) ([]runtime.Object, error) {
+	return nil, nil
+}
+
+func (r *stubRunner) ValidateManifests(ctx context.Context, objects []runtime.Object) error {
+	return nil
+}
+
+func (r *stubRunner) RunPreflightChecks(ctx context.Context) []*evaluator.PreflightCheck {
+	return nil
+}
+
+func (r *stubRunner) RunID() string {
+	return "stub-run"
+}
+
+func newEvaluation(name string, spec map[string]interface{}) *unstructured.Unstructured {
+	object := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": spec,
+		},
+	}
+	object.SetAPIVersion(Group + "/" + Version)
+	object.SetKind(Kind)
+	object.SetName(name)
+	object.SetGeneration(1)
+	return object
+}
+
+func TestReconcile(t *testing.T) {
+	testService := map[string]interface{}{
+		"image": "nadunrds/k8s-node-perf-evaluator-test-service:latest",
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{GroupVersionResource: Kind + "List"},
+		newEvaluation("nightly", map[string]interface{}{
+			"testService": testService,
+			"nodeSelector": map[string]interface{}{
+				"labelSelector": "pool=general",
+			},
+			"thresholds": map[string]interface{}{
+				"suites": []interface{}{
+					map[string]interface{}{
+						"suite":             "Ping Test",
+						"maxAverageLatency": "1s",
+					},
+				},
+			},
+		}),
+		newEvaluation("invalid", map[string]interface{}{
+			"testService": testService,
+			"timeouts": map[string]interface{}{
+				"deploymentReady": "soon",
+			},
+		}),
+		newEvaluation("missing-image", map[string]interface{}{
+			"nodeSelector": map[string]interface{}{
+				"labelSelector": "pool=general",
+			},
+		}),
+		newEvaluation("unsupported", map[string]interface{}{
+			"namespace": "kube-system",
+			"remediation": map[string]interface{}{
+				"enabled": true,
+			},
+		}),
+	)
+	runs := []*stubRunner{}
+	c := New(dynamicClient, func(config *config.Config) evaluator.TestRunnerInterface {
+		runner := &stubRunner{config: config}
+		runs = append(runs, runner)
+		return runner
+	}, zap.NewNop().Sugar())
+
+	ctx := context.Background()
+	err := c.Reconcile(ctx, "nightly")
+	if err != nil {
+		t.Fatalf("failed to reconcile evaluation: %v", err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("expected a single run, but got %d", len(runs))
+	}
+	if runs[0].config.Namespace != "node-perf-nightly" || runs[0].config.NodeSelector.LabelSelector != "pool=general" {
+		t.Errorf("expected the config to be read from the spec, but got %+v", runs[0].config)
+	}
+	status := getStatus(t, c, "nightly")
+	if status.Phase != PhaseSucceeded || status.ObservedGeneration != 1 || status.CompletionTime == nil {
+		t.Errorf("expected the evaluation to succeed, but got %+v", status)
+	}
+	if status.PassedNodes != 1 || status.FailedNodes != 1 || len(status.Nodes) != 2 {
+		t.Fatalf("expected a passed and a failed node, but got %+v", status)
+	}
+	if status.Nodes[1].Name != "node-b" || status.Nodes[1].Passed || len(status.Nodes[1].Violations) != 1 ||
+		status.Nodes[1].Results[0].AverageLatency != "3s" {
+		t.Errorf("expected node-b to fail the latency threshold, but got %+v", status.Nodes[1])
+	}
+
+	// The same generation is not evaluated again
+	err = c.Reconcile(ctx, "nightly")
+	if err != nil {
+		t.Fatalf("failed to reconcile evaluation: %v", err)
+	}
+	if len(runs) != 1 {
+		t.Errorf("expected the completed evaluation not to be run again, but got %d runs", len(runs))
+	}
+
+	err = c.Reconcile(ctx, "invalid")
+	if err != nil {
+		t.Fatalf("failed to reconcile evaluation: %v", err)
+	}
+	status = getStatus(t, c, "invalid")
+	if status.Phase != PhaseFailed || status.Message == "" || len(runs) != 1 {
+		t.Errorf("expected the invalid evaluation to fail without running, but got %+v", status)
+	}
+
+	err = c.Reconcile(ctx, "missing-image")
+	if err != nil {
+		t.Fatalf("failed to reconcile evaluation: %v", err)
+	}
+	status = getStatus(t, c, "missing-image")
+	if status.Phase != PhaseFailed || status.Message != "testService.image is required in spec" || len(runs) != 1 {
+		t.Errorf("expected the evaluation without an image to fail without running, but got %+v", status)
+	}
+
+	err = c.Reconcile(ctx, "unsupported")
+	if err != nil {
+		t.Fatalf("failed to reconcile evaluation: %v", err)
+	}
+	status = getStatus(t, c, "unsupported")
+	if status.Phase != PhaseFailed || status.Message != "unsupported fields in spec: [namespace remediation]" ||
+		len(runs) != 1 {
+		t.Errorf("expected the evaluation with unsupported fields to fail without running, but got %+v", status)
+	}
+
+	err = c.Reconcile(ctx, "deleted")
+	if err != nil {
+		t.Errorf("expected missing evaluations to be ignored, but got %v", err)
+	}
+}
+
+func getStatus(t *testing.T, c *Controller, name string) *NodePerfEvaluationStatus {
+	object, err := c.dynamicClient.Resource(GroupVersionResource).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get evaluation %s: %v", name, err)
+	}
+	status, err := readStatus(object)
+	if err != nil {
+		t.Fatalf("failed to read status of %s: %v", name, err)
+	}
+	return status
+}
+
+// jsonSchema is the part of the schema of a custom resource definition which describes the properties.
+type jsonSchema struct {
+	Type       string                `json:"type"`
+	Properties map[string]jsonSchema `json:"properties"`
+}
+
+func TestSpecSchema(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("..", "..", "deploy", "nodeperfevaluations.yaml"))
+	if err != nil {
+		t.Fatalf("failed to read custom resource definition: %v", err)
+	}
+	crd := &struct {
+		Spec struct {
+			Versions []struct {
+				Schema struct {
+					OpenAPIV3Schema jsonSchema `json:"openAPIV3Schema"`
+				} `json:"schema"`
+			} `json:"versions"`
+		} `json:"spec"`
+	}{}
+	err = yaml.Unmarshal(content, crd)
+	if err != nil {
+		t.Fatalf("failed to parse custom resource definition: %v", err)
+	}
+
+	// The schema of the spec has a property for each of the config fields which can be set in the spec
+	schemaFields := []string{}
+	var collectFields func(schema jsonSchema, prefix string)
+	collectFields = func(schema jsonSchema, prefix string) {
+		if schema.Type != "object" || len(schema.Properties) == 0 {
+			schemaFields = append(schemaFields, prefix)
+			return
+		}
+		for name, property := range schema.Properties {
+			collectFields(property, strings.TrimPrefix(prefix+"."+name, "."))
+		}
+	}
+	collectFields(crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"], "")
+	configFields := []string{}
+	for _, field := range config.Fields() {
+		if slices.Contains(specFields, strings.Split(field.Path, ".")[0]) {
+			configFields = append(configFields, field.Path)
+		}
+	}
+	slices.Sort(schemaFields)
+	slices.Sort(configFields)
+	if !slices.Equal(schemaFields, configFields) {
+		t.Errorf("expected the schema of the spec to have the fields %v, but got %v", configFields, schemaFields)
+	}
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/reports"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	Group    = "nodeperf.nadundesilva.github.io"
+	Version  = "v1alpha1"
+	Kind     = "NodePerfEvaluation"
+	Resource = "nodeperfevaluations"
+)
+
+var GroupVersionResource = schema.GroupVersionResource{
+	Group:    Group,
+	Version:  Version,
+	Resource: Resource,
+}
+
+type Phase string
+
+const (
+	PhaseRunning   Phase = "Running"
+	PhaseSucceeded Phase = "Succeeded"
+	PhaseFailed    Phase = "Failed"
+)
+
+// NodePerfEvaluationStatus is the status of a NodePerfEvaluation. The spec of a NodePerfEvaluation
+// has the same fields as the config file.
+type NodePerfEvaluationStatus struct {
+	ObservedGeneration int64        `json:"observedGeneration,omitempty"`
+	Phase              Phase        `json:"phase,omitempty"`
+	Message            string       `json:"message,omitempty"`
+	StartTime          *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime     *metav1.Time `json:"completionTime,omitempty"`
+	PassedNodes        int64        `json:"passedNodes"`
+	FailedNodes        int64        `json:"failedNodes"`
+	Nodes              []NodeStatus `json:"nodes,omitempty"`
+}
+
+type NodeStatus struct {
+	Name       string        `json:"name"`
+	Passed     bool          `json:"passed"`
+	Violations []string      `json:"violations,omitempty"`
+	Results    []SuiteResult `json:"results,omitempty"`
+}
+
+type SuiteResult struct {
+	Suite                     string `json:"suite"`
+	RequestCount              int64  `json:"requestCount"`
+	AverageLatency            string `json:"averageLatency"`
+	FailedPercentage          string `json:"failedPercentage"`
+	ProvisioningFailed        bool   `json:"provisioningFailed,omitempty"`
+	ProvisioningFailureReason string `json:"provisioningFailureReason,omitempty"`
+}
+
+func newNodeStatuses(testSuiteResults []*reports.TestSuiteResult, evaluations []*reports.NodeEvaluation) []NodeStatus {
+	nodes := []NodeStatus{}
+	for _, evaluation := range evaluations {
+		node := NodeStatus{
+			Name:       evaluation.NodeName,
+			Passed:     evaluation.Passed,
+			Violations: evaluation.Violations,
+			Results:    []SuiteResult{},
+		}
+		for _, testSuiteResult := range testSuiteResults {
+			for _, testResult := range testSuiteResult.TestResults {
+				if testResult.NodeName != evaluation.NodeName {
+					continue
+				}
+				node.Results = append(node.Results, SuiteResult{
+					Suite:                     testSuiteResult.Name,
+					RequestCount:              int64(testResult.RequestCount),
+					AverageLatency:            testResult.AverageLatency.String(),
+					FailedPercentage:          fmt.Sprintf("%.2f", testResult.FailedPercentage),
+					ProvisioningFailed:        testResult.ProvisioningFailed,
+					ProvisioningFailureReason: testResult.ProvisioningFailureReason,
+				})
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+func readStatus(object *unstructured.Unstructured) (*NodePerfEvaluationStatus, error) {
+	status := &NodePerfEvaluationStatus{}
+	statusContent, ok := object.Object["status"].(map[string]interface{})
+	if !ok {
+		return status, nil
+	}
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(statusContent, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse status of %s: %w", object.GetName(), err)
+	}
+	return status, nil
+}
+
+func writeStatus(object *unstructured.Unstructured, status *NodePerfEvaluationStatus) error {
+	statusContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(status)
+	if err != nil {
+		return fmt.Errorf("failed to generate status of %s: %w", object.GetName(), err)
+	}
+	object.Object["status"] = statusContent
+	return nil
+}
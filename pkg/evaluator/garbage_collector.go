@@ -0,0 +1,148 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/k8s"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type GarbageCollectorInterface interface {
+	FindLeakedResources(ctx context.Context, olderThan time.Duration) ([]*LeakedResource, error)
+	DeleteLeakedResources(ctx context.Context, resources []*LeakedResource) error
+}
+
+type garbageCollector struct {
+	logger    *zap.SugaredLogger
+	k8sClient k8s.Interface
+	now       func() time.Time
+}
+
+// LeakedResource is a resource created by the evaluator which was left behind (e.g. by a crashed run).
+type LeakedResource struct {
+	Kind              string
+	Namespace         string
+	Name              string
+	RunID             string
+	CreationTimestamp time.Time
+}
+
+const (
+	kindNamespace  = "Namespace"
+	kindDeployment = "Deployment"
+	kindService    = "Service"
+	kindIngress    = "Ingress"
+)
+
+func NewGarbageCollector(k8sClient k8s.Interface, logger *zap.SugaredLogger) GarbageCollectorInterface {
+	return &garbageCollector{
+		logger:    logger,
+		k8sClient: k8sClient,
+		now:       time.Now,
+	}
+}
+
+// FindLeakedResources finds the namespaces and the objects created by the evaluator (carrying the run ID
+// label) across the cluster which are older than the provided age. Objects inside a leaked namespace are not listed
+// separately since they are removed along with the namespace.
+func (gc *garbageCollector) FindLeakedResources(ctx context.Context, olderThan time.Duration) ([]*LeakedResource, error) {
+	cutoff := gc.now().Add(-olderThan)
+	resources := []*LeakedResource{}
+	leakedNamespaces := map[string]struct{}{}
+	isLeaked := func(object metav1.Object) bool {
+		return object.GetCreationTimestamp().Time.Before(cutoff)
+	}
+	newLeakedResource := func(kind string, object metav1.Object) *LeakedResource {
+		return &LeakedResource{
+			Kind:              kind,
+			Namespace:         object.GetNamespace(),
+			Name:              object.GetName(),
+			RunID:             object.GetLabels()[runIDLabel],
+			CreationTimestamp: object.GetCreationTimestamp().Time,
+		}
+	}
+
+	namespaces, err := gc.k8sClient.ListNamespaces(ctx, k8s.Selector{
+		LabelSelector: managedByLabel + "=" + managedByValue,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	for _, namespace := range namespaces.Items {
+		if isLeaked(&namespace) {
+			resources = append(resources, newLeakedResource(kindNamespace, &namespace))
+			leakedNamespaces[namespace.GetName()] = struct{}{}
+		}
+	}
+
+	// The run ID label is required as well since workloads of the users (e.g. the evaluator itself) may share
+	// the app label
+	selector := k8s.Selector{
+		LabelSelector: appLabel + "=" + appLabelValue + "," + runIDLabel,
+	}
+	objects := []*LeakedResource{}
+	deployments, err := gc.k8sClient.ListDeployments(ctx, metav1.NamespaceAll, selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for _, deployment := range deployments.Items {
+		if isLeaked(&deployment) {
+			objects = append(objects, newLeakedResource(kindDeployment, &deployment))
+		}
+	}
+	services, err := gc.k8sClient.ListServices(ctx, metav1.NamespaceAll, selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	for _, service := range services.Items {
+		if isLeaked(&service) {
+			objects = append(objects, newLeakedResource(kindService, &service))
+		}
+	}
+	ingresses, err := gc.k8sClient.ListIngresses(ctx, metav1.NamespaceAll, selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+	for _, ingress := range ingresses.Items {
+		if isLeaked(&ingress) {
+			objects = append(objects, newLeakedResource(kindIngress, &ingress))
+		}
+	}
+
+	for _, object := range objects {
+		if _, ok := leakedNamespaces[object.Namespace]; !ok {
+			resources = append(resources, object)
+		}
+	}
+	return resources, nil
+}
+
+// DeleteLeakedResources deletes the resources and continues with the rest if the deletion of a resource fails.
+func (gc *garbageCollector) DeleteLeakedResources(ctx context.Context, resources []*LeakedResource) error {
+	errs := []error{}
+	for _, resource := range resources {
+		var err error
+		switch resource.Kind {
+		case kindNamespace:
+			err = gc.k8sClient.DeleteNamespace(ctx, resource.Name)
+		case kindDeployment:
+			err = gc.k8sClient.DeleteDeployment(ctx, resource.Namespace, resource.Name)
+		case kindService:
+			err = gc.k8sClient.DeleteService(ctx, resource.Namespace, resource.Name)
+		case kindIngress:
+			err = gc.k8sClient.DeleteIngress(ctx, resource.Namespace, resource.Name)
+		default:
+			err = fmt.Errorf("unknown resource kind %s", resource.Kind)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete %s %s/%s: %w", resource.Kind, resource.Namespace, resource.Name, err))
+			continue
+		}
+		gc.logger.Infow("deleted leaked resource", "kind", resource.Kind, "namespace", resource.Namespace, "name", resource.Name)
+	}
+	return errors.Join(errs...)
+}
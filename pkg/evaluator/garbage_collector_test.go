@@ -0,0 +1,122 @@
+package evaluator
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/k8s"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGarbageCollector(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	old := metav1.NewTime(now.Add(-2 * time.Hour))
+	recent := metav1.NewTime(now.Add(-10 * time.Minute))
+	evaluatorNamespaceLabels := map[string]string{
+		managedByLabel: managedByValue,
+		runIDLabel:     "old-run",
+	}
+	evaluatorObjectLabels := map[string]string{
+		appLabel:   appLabelValue,
+		runIDLabel: "old-run",
+	}
+	objectMeta := func(namespace string, name string, labels map[string]string, creationTimestamp metav1.Time) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Namespace:         namespace,
+			Name:              name,
+			Labels:            labels,
+			CreationTimestamp: creationTimestamp,
+		}
+	}
+
+	clientset := fake.NewClientset([]runtime.Object{
+		&corev1.Namespace{ObjectMeta: objectMeta("", "leaked-namespace", evaluatorNamespaceLabels, old)},
+		&corev1.Namespace{ObjectMeta: objectMeta("", "running-namespace", evaluatorNamespaceLabels, recent)},
+		&corev1.Namespace{ObjectMeta: objectMeta("", "production", map[string]string{}, old)},
+		&appsv1.Deployment{ObjectMeta: objectMeta("leaked-namespace", "test-service-a", evaluatorObjectLabels, old)},
+		&appsv1.Deployment{ObjectMeta: objectMeta("default", "test-service-b", evaluatorObjectLabels, old)},
+		&appsv1.Deployment{ObjectMeta: objectMeta("default", "test-service-c", evaluatorObjectLabels, recent)},
+		&appsv1.Deployment{ObjectMeta: objectMeta("production", "api", map[string]string{"app": "api"}, old)},
+		&appsv1.Deployment{ObjectMeta: objectMeta("production", "evaluator", map[string]string{appLabel: appLabelValue}, old)},
+		&corev1.Service{ObjectMeta: objectMeta("default", "test-service-b", evaluatorObjectLabels, old)},
+		&networkingv1.Ingress{ObjectMeta: objectMeta("default", "test-service-b", evaluatorObjectLabels, old)},
+	}...)
+	gc := &garbageCollector{
+		logger:    zap.NewNop().Sugar(),
+		k8sClient: k8s.NewFromClientset(clientset),
+		now: func() time.Time {
+			return now
+		},
+	}
+	ctx := context.Background()
+
+	resources, err := gc.FindLeakedResources(ctx, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to find leaked resources: %v", err)
+	}
+	found := []string{}
+	for _, resource := range resources {
+		found = append(found, resource.Kind+"/"+resource.Namespace+"/"+resource.Name)
+	}
+	sort.Strings(found)
+	expected := []string{
+		"Deployment/default/test-service-b",
+		"Ingress/default/test-service-b",
+		"Namespace//leaked-namespace",
+		"Service/default/test-service-b",
+	}
+	if len(found) != len(expected) {
+		t.Fatalf("expected leaked resources %v, but found %v", expected, found)
+	}
+	for i := range expected {
+		if found[i] != expected[i] {
+			t.Fatalf("expected leaked resources %v, but found %v", expected, found)
+		}
+	}
+
+	err = gc.DeleteLeakedResources(ctx, resources)
+	if err != nil {
+		t.Fatalf("failed to delete leaked resources: %v", err)
+	}
+	namespaces, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list namespaces: %v", err)
+	}
+	if len(namespaces.Items) != 2 {
+		t.Errorf("expected 2 namespaces to remain, but found %d", len(namespaces.Items))
+	}
+	deployments, err := clientset.AppsV1().Deployments(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list deployments: %v", err)
+	}
+	for _, deployment := range deployments.Items {
+		if deployment.GetNamespace() == "default" && deployment.GetName() == "test-service-b" {
+			t.Errorf("expected deployment default/test-service-b to be deleted")
+		}
+	}
+	if len(deployments.Items) != 4 {
+		t.Errorf("expected 4 deployments to remain, but found %d", len(deployments.Items))
+	}
+	services, err := clientset.CoreV1().Services("default").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list services: %v", err)
+	}
+	if len(services.Items) != 0 {
+		t.Errorf("expected all services to be deleted, but found %d", len(services.Items))
+	}
+	ingresses, err := clientset.NetworkingV1().Ingresses("default").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list ingresses: %v", err)
+	}
+	if len(ingresses.Items) != 0 {
+		t.Errorf("expected all ingresses to be deleted, but found %d", len(ingresses.Items))
+	}
+}
@@ -1,6 +1,7 @@
 package evaluator
 
 import (
+	"encoding/json"
 	"fmt"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -8,38 +9,54 @@ import (
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
 )
 
 const testServicePort = 8080
 const testServicePortName = "http-port"
+const testServiceContainerName = "test-service"
+const hostnameLabel = "kubernetes.io/hostname"
+const managedByLabel = "app.kubernetes.io/managed-by"
+const managedByValue = "k8s-node-perf-evaluator"
+const runIDLabel = "k8s-node-perf-evaluator/run-id"
+const appLabel = "app"
+const appLabelValue = "k8s-node-perf-evaluator"
 
 func (runner *testRunner) makeNamespace(namespace string) *corev1.Namespace {
 	return &corev1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: namespace,
+			Labels: map[string]string{
+				managedByLabel: managedByValue,
+				runIDLabel:     runner.runID,
+			},
 		},
 	}
 }
 
-func (runner *testRunner) makeDeployment(testService TestService) *appsv1.Deployment {
-	return &appsv1.Deployment{
+func (runner *testRunner) makeDeployment(testService TestService) (*appsv1.Deployment, error) {
+	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      makeName(testService),
-			Namespace: runner.config.Namespace,
+			Namespace: runner.namespace,
 		},
 		Spec: appsv1.DeploymentSpec{
 			Selector: &metav1.LabelSelector{
-				MatchLabels: makeLabels(testService),
+				MatchLabels: runner.makeLabels(testService),
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: makeLabels(testService),
+					Labels: runner.makeLabels(testService),
 				},
 				Spec: corev1.PodSpec{
+					Tolerations:       runner.makeTolerations(),
+					PriorityClassName: runner.config.TestService.Scheduling.PriorityClassName,
+					RuntimeClassName:  runner.config.TestService.Scheduling.RuntimeClassName,
 					Containers: []corev1.Container{
 						{
-							Name:  "test-service",
+							Name:  testServiceContainerName,
 							Image: runner.config.TestService.Image,
 							Ports: []corev1.ContainerPort{
 								{
@@ -68,7 +85,120 @@ func (runner *testRunner) makeDeployment(testService TestService) *appsv1.Deploy
 							},
 						},
 					},
-					NodeName: testService.NodeName,
+				},
+			},
+		},
+	}
+	if runner.config.TestService.Scheduling.UseNodeAffinity {
+		deployment.Spec.Template.Spec.Affinity = makeNodeAffinity(testService)
+	} else {
+		deployment.Spec.Template.Spec.NodeName = testService.NodeName
+	}
+
+	podTemplates := []map[string]interface{}{}
+	if runner.config.TestService.PodTemplate != nil {
+		podTemplates = append(podTemplates, runner.config.TestService.PodTemplate)
+	}
+	for _, override := range runner.config.TestService.NodeOverrides {
+		selector, err := labels.Parse(override.LabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse node override label selector %q: %w", override.LabelSelector, err)
+		}
+		if selector.Matches(labels.Set(testService.NodeLabels)) {
+			podTemplates = append(podTemplates, override.PodTemplate)
+		}
+	}
+	for _, podTemplate := range podTemplates {
+		var err error
+		deployment, err = applyPodTemplatePatch(deployment, podTemplate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return deployment, nil
+}
+
+// validatePodTemplates checks that the pod template and the pod templates of all the node overrides can be applied,
+// so that a mistake in the config fails the run instead of the provisioning of the test service on every node.
+func (runner *testRunner) validatePodTemplates() error {
+	deployment, err := runner.makeDeployment(TestService{})
+	if err != nil {
+		return err
+	}
+	for _, override := range runner.config.TestService.NodeOverrides {
+		_, err = applyPodTemplatePatch(deployment, override.PodTemplate)
+		if err != nil {
+			return fmt.Errorf("invalid pod template of node override %q: %w", override.LabelSelector, err)
+		}
+	}
+	return nil
+}
+
+func applyPodTemplatePatch(deployment *appsv1.Deployment, podTemplate map[string]interface{}) (*appsv1.Deployment, error) {
+	original, err := json.Marshal(deployment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert deployment to json: %w", err)
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": podTemplate,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert pod template patch to json: %w", err)
+	}
+	patched, err := strategicpatch.StrategicMergePatch(original, patch, appsv1.Deployment{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply pod template patch: %w", err)
+	}
+	patchedDeployment := &appsv1.Deployment{}
+	err = json.Unmarshal(patched, patchedDeployment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse patched deployment: %w", err)
+	}
+	return patchedDeployment, nil
+}
+
+func (runner *testRunner) makeTolerations() []corev1.Toleration {
+	scheduling := runner.config.TestService.Scheduling
+	if scheduling.TolerateAll {
+		return []corev1.Toleration{
+			{
+				Operator: corev1.TolerationOpExists,
+			},
+		}
+	}
+	tolerations := []corev1.Toleration{}
+	for _, toleration := range scheduling.Tolerations {
+		tolerations = append(tolerations, corev1.Toleration{
+			Key:               toleration.Key,
+			Operator:          corev1.TolerationOperator(toleration.Operator),
+			Value:             toleration.Value,
+			Effect:            corev1.TaintEffect(toleration.Effect),
+			TolerationSeconds: toleration.TolerationSeconds,
+		})
+	}
+	return tolerations
+}
+
+func makeNodeAffinity(testService TestService) *corev1.Affinity {
+	hostname, ok := testService.NodeLabels[hostnameLabel]
+	if !ok {
+		hostname = testService.NodeName
+	}
+	return &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{
+						MatchExpressions: []corev1.NodeSelectorRequirement{
+							{
+								Key:      hostnameLabel,
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{hostname},
+							},
+						},
+					},
 				},
 			},
 		},
@@ -79,11 +209,11 @@ func (runner *testRunner) makeService(testService TestService) *corev1.Service {
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      makeName(testService),
-			Namespace: runner.config.Namespace,
-			Labels:    makeLabels(testService),
+			Namespace: runner.namespace,
+			Labels:    runner.makeLabels(testService),
 		},
 		Spec: corev1.ServiceSpec{
-			Selector: makeLabels(testService),
+			Selector: runner.makeLabels(testService),
 			Ports: []corev1.ServicePort{
 				{
 					Name:       testServicePortName,
@@ -101,8 +231,8 @@ func (runner *testRunner) makeIngress(testService TestService) *networkingv1.Ing
 	return &networkingv1.Ingress{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        makeName(testService),
-			Namespace:   runner.config.Namespace,
-			Labels:      makeLabels(testService),
+			Namespace:   runner.namespace,
+			Labels:      runner.makeLabels(testService),
 			Annotations: runner.config.Ingress.Annotations,
 		},
 		Spec: networkingv1.IngressSpec{
@@ -153,10 +283,11 @@ func makeName(testService TestService) string {
 	return fmt.Sprintf("test-service-%s", testService.UUID)
 }
 
-func makeLabels(testService TestService) map[string]string {
+func (runner *testRunner) makeLabels(testService TestService) map[string]string {
 	return map[string]string{
-		"node": testService.NodeName,
-		"type": "test-service",
-		"app":  "k8s-node-perf-evaluator",
+		runIDLabel: runner.runID,
+		"node":     testService.NodeName,
+		"type":     "test-service",
+		appLabel:   appLabelValue,
 	}
 }
@@ -0,0 +1,121 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ClusterTestRunner is the test runner of one of the clusters evaluated together.
+type ClusterTestRunner struct {
+	Cluster string
+	Runner  TestRunnerInterface
+}
+
+type multiClusterTestRunner struct {
+	runID   string
+	logger  *zap.SugaredLogger
+	runners []*ClusterTestRunner
+}
+
+var _ TestRunnerInterface = (*multiClusterTestRunner)(nil)
+
+// NewMultiClusterTestRunner creates a test runner which evaluates the clusters concurrently and combines
+// their test suites. The tests are marked with the cluster they were run in, and hence the cluster names
+// are expected to be unique. The run ID is expected to be the run ID shared by the runners of the clusters.
+func NewMultiClusterTestRunner(runID string, runners []*ClusterTestRunner, logger *zap.SugaredLogger) TestRunnerInterface {
+	return &multiClusterTestRunner{
+		runID:   runID,
+		logger:  logger,
+		runners: runners,
+	}
+}
+
+func (r *multiClusterTestRunner) RunID() string {
+	return r.runID
+}
+
+// RunTest runs the tests in all the clusters and returns the test suites completed in any of the clusters,
+// along with the errors of the clusters which failed.
+func (r *multiClusterTestRunner) RunTest(ctx context.Context) ([]*TestSuite, error) {
+	clusterTestSuites := make([][]*TestSuite, len(r.runners))
+	clusterErrs := make([]error, len(r.runners))
+	wg := sync.WaitGroup{}
+	for i, runner := range r.runners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.logger.Infow("evaluating cluster", "cluster", runner.Cluster)
+			testSuites, err := runner.Runner.RunTest(ctx)
+			for _, testSuite := range testSuites {
+				for _, test := range testSuite.Tests {
+					test.Cluster = runner.Cluster
+				}
+			}
+			clusterTestSuites[i] = testSuites
+			if err != nil {
+				clusterErrs[i] = fmt.Errorf("failed to evaluate cluster %s: %w", runner.Cluster, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Test suites of the clusters are merged by name, keeping the order in which they were run
+	testSuites := []*TestSuite{}
+	testSuitesByName := map[string]*TestSuite{}
+	for _, clusterSuites := range clusterTestSuites {
+		for _, clusterSuite := range clusterSuites {
+			testSuite, ok := testSuitesByName[clusterSuite.Name]
+			if !ok {
+				testSuite = &TestSuite{Name: clusterSuite.Name}
+				testSuitesByName[clusterSuite.Name] = testSuite
+				testSuites = append(testSuites, testSuite)
+			}
+			testSuite.Tests = append(testSuite.Tests, clusterSuite.Tests...)
+		}
+	}
+	return testSuites, errors.Join(clusterErrs...)
+}
+
+func (r *multiClusterTestRunner) RenderManifests(ctx context.Context) ([]runtime.Object, error) {
+	objects := []runtime.Object{}
+	for _, runner := range r.runners {
+		clusterObjects, err := runner.Runner.RenderManifests(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render manifests of cluster %s: %w", runner.Cluster, err)
+		}
+		objects = append(objects, clusterObjects...)
+	}
+	return objects, nil
+}
+
+// ValidateManifests validates the manifests of each cluster against its own API server. The provided objects
+// are ignored since the objects of each cluster are rendered again.
+func (r *multiClusterTestRunner) ValidateManifests(ctx context.Context, objects []runtime.Object) error {
+	for _, runner := range r.runners {
+		clusterObjects, err := runner.Runner.RenderManifests(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to render manifests of cluster %s: %w", runner.Cluster, err)
+		}
+		err = runner.Runner.ValidateManifests(ctx, clusterObjects)
+		if err != nil {
+			return fmt.Errorf("failed to validate manifests of cluster %s: %w", runner.Cluster, err)
+		}
+	}
+	return nil
+}
+
+func (r *multiClusterTestRunner) RunPreflightChecks(ctx context.Context) []*PreflightCheck {
+	checks := []*PreflightCheck{}
+	for _, runner := range r.runners {
+		for _, check := range runner.Runner.RunPreflightChecks(ctx) {
+			check.Name = fmt.Sprintf("[%s] %s", runner.Cluster, check.Name)
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
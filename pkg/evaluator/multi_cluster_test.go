@@ -0,0 +1,74 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// stubClusterRunner returns the same test suites on every run.
+type stubClusterRunner struct {
+	testSuites []*TestSuite
+	err        error
+}
+
+func (r *stubClusterRunner) RunTest(ctx context.Context) ([]*TestSuite, error) {
+	return r.testSuites, r.err
+}
+
+func (r *stubClusterRunner) RenderManifests(ctx context.Context) ([]runtime.Object, error) {
+	return nil, nil
+}
+
+func (r *stubClusterRunner) ValidateManifests(ctx context.Context, objects []runtime.Object) error {
+	return nil
+}
+
+func (r *stubClusterRunner) RunPreflightChecks(ctx context.Context) []*PreflightCheck {
+	return []*PreflightCheck{{Name: "Nodes", Status: PreflightPassed}}
+}
+
+func (r *stubClusterRunner) RunID() string {
+	return "stub-run"
+}
+
+func TestMultiClusterTestRunner(t *testing.T) {
+	east := &stubClusterRunner{testSuites: []*TestSuite{
+		{Name: "Ping Test", Tests: []*Test{{NodeName: "node-a"}}},
+		{Name: "CPU Intensive Load Test", Tests: []*Test{{NodeName: "node-a"}}},
+	}}
+	// The west cluster failed after completing only the first test suite
+	west := &stubClusterRunner{
+		testSuites: []*TestSuite{{Name: "Ping Test", Tests: []*Test{{NodeName: "node-a"}}}},
+		err:        errors.New("ingress did not become ready"),
+	}
+	testRunner := NewMultiClusterTestRunner("stub-run", []*ClusterTestRunner{
+		{Cluster: "east", Runner: east},
+		{Cluster: "west", Runner: west},
+	}, zap.NewNop().Sugar())
+
+	if testRunner.RunID() != "stub-run" {
+		t.Errorf("expected the run ID shared by the clusters, but got %s", testRunner.RunID())
+	}
+	testSuites, err := testRunner.RunTest(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "failed to evaluate cluster west") {
+		t.Errorf("expected the error of the west cluster, but got %v", err)
+	}
+	if len(testSuites) != 2 || testSuites[0].Name != "Ping Test" || len(testSuites[0].Tests) != 2 ||
+		len(testSuites[1].Tests) != 1 {
+		t.Fatalf("expected the test suites of the clusters to be merged, but got %+v", testSuites)
+	}
+	if testSuites[0].Tests[0].Cluster != "east" || testSuites[0].Tests[1].Cluster != "west" {
+		t.Errorf("expected the tests to be marked with their clusters, but got %s and %s",
+			testSuites[0].Tests[0].Cluster, testSuites[0].Tests[1].Cluster)
+	}
+
+	checks := testRunner.RunPreflightChecks(context.Background())
+	if len(checks) != 2 || checks[0].Name != "[east] Nodes" || checks[1].Name != "[west] Nodes" {
+		t.Errorf("expected the preflight checks of each cluster, but got %+v", checks)
+	}
+}
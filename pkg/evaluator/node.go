@@ -0,0 +1,176 @@
+package evaluator
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// Node holds the metadata of a tested node captured at the time of the test.
+type Node struct {
+	Name         string
+	Labels       map[string]string
+	InstanceType string
+	Zone         string
+	NodePool     string
+
+	KernelVersion           string
+	OSImage                 string
+	ContainerRuntimeVersion string
+	KubeletVersion          string
+
+	Capacity          corev1.ResourceList
+	Allocatable       corev1.ResourceList
+	CreationTimestamp time.Time
+
+	Conditions    []NodeCondition
+	Taints        []corev1.Taint
+	WarningEvents []NodeEvent
+
+	// UtilisationBeforeTest is the utilisation of the node before the test services were provisioned
+	UtilisationBeforeTest *NodeUtilisation
+}
+
+type NodeCondition struct {
+	Type               string
+	Status             string
+	Reason             string
+	Message            string
+	LastTransitionTime time.Time
+}
+
+type NodeEvent struct {
+	Reason        string
+	Message       string
+	Count         int32
+	LastTimestamp time.Time
+}
+
+// Unhealthy returns true if the node condition indicates a problem (e.g. memory pressure or not ready).
+func (condition NodeCondition) Unhealthy() bool {
+	if condition.Type == string(corev1.NodeReady) {
+		return condition.Status != string(corev1.ConditionTrue)
+	}
+	return condition.Status != string(corev1.ConditionFalse)
+}
+
+var snapshotConditionTypes = []corev1.NodeConditionType{
+	corev1.NodeReady,
+	corev1.NodeMemoryPressure,
+	corev1.NodeDiskPressure,
+	corev1.NodePIDPressure,
+	corev1.NodeNetworkUnavailable,
+}
+
+const (
+	recentNodeEventsWindow = time.Hour
+	recentNodeEventsCount  = 5
+)
+
+var instanceTypeLabels = []string{
+	corev1.LabelInstanceTypeStable,
+	corev1.LabelInstanceType,
+}
+
+var zoneLabels = []string{
+	corev1.LabelTopologyZone,
+	corev1.LabelFailureDomainBetaZone,
+}
+
+var nodePoolLabels = []string{
+	"cloud.google.com/gke-nodepool",
+	"eks.amazonaws.com/nodegroup",
+	"karpenter.sh/nodepool",
+	"kubernetes.azure.com/agentpool",
+	"agentpool",
+	"doks.digitalocean.com/node-pool",
+}
+
+func newNode(node *corev1.Node) *Node {
+	return &Node{
+		Name:                    node.GetName(),
+		Labels:                  node.GetLabels(),
+		InstanceType:            firstLabelValue(node.GetLabels(), instanceTypeLabels),
+		Zone:                    firstLabelValue(node.GetLabels(), zoneLabels),
+		NodePool:                firstLabelValue(node.GetLabels(), nodePoolLabels),
+		KernelVersion:           node.Status.NodeInfo.KernelVersion,
+		OSImage:                 node.Status.NodeInfo.OSImage,
+		ContainerRuntimeVersion: node.Status.NodeInfo.ContainerRuntimeVersion,
+		KubeletVersion:          node.Status.NodeInfo.KubeletVersion,
+		Capacity:                node.Status.Capacity,
+		Allocatable:             node.Status.Allocatable,
+		CreationTimestamp:       node.GetCreationTimestamp().Time,
+		Conditions:              newNodeConditions(node),
+		Taints:                  node.Spec.Taints,
+		WarningEvents:           []NodeEvent{},
+	}
+}
+
+func newNodeConditions(node *corev1.Node) []NodeCondition {
+	conditions := []NodeCondition{}
+	for _, conditionType := range snapshotConditionTypes {
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == conditionType {
+				conditions = append(conditions, NodeCondition{
+					Type:               string(condition.Type),
+					Status:             string(condition.Status),
+					Reason:             condition.Reason,
+					Message:            condition.Message,
+					LastTransitionTime: condition.LastTransitionTime.Time,
+				})
+			}
+		}
+	}
+	return conditions
+}
+
+// snapshotNodes captures the metadata, the conditions and the recent warning events of the nodes.
+func (runner *testRunner) snapshotNodes(ctx context.Context, nodesList *corev1.NodeList) map[string]*Node {
+	nodes := map[string]*Node{}
+	for _, node := range nodesList.Items {
+		nodes[node.GetName()] = newNode(&node)
+	}
+
+	events, err := runner.k8sClient.ListEvents(ctx, metav1.NamespaceAll, k8s.Selector{
+		FieldSelector: fields.Set{
+			"involvedObject.kind": "Node",
+			"type":                corev1.EventTypeWarning,
+		}.String(),
+	})
+	if err != nil {
+		runner.logger.Warnw("failed to list the warning events of the nodes", "error", err)
+		return nodes
+	}
+	sort.Slice(events.Items, func(i, j int) bool {
+		return k8s.EventTime(&events.Items[i]).After(k8s.EventTime(&events.Items[j]))
+	})
+	cutoff := time.Now().Add(-recentNodeEventsWindow)
+	for _, event := range events.Items {
+		node, ok := nodes[event.InvolvedObject.Name]
+		if !ok || event.Type != corev1.EventTypeWarning || event.InvolvedObject.Kind != "Node" ||
+			k8s.EventTime(&event).Before(cutoff) || len(node.WarningEvents) >= recentNodeEventsCount {
+			continue
+		}
+		node.WarningEvents = append(node.WarningEvents, NodeEvent{
+			Reason:        event.Reason,
+			Message:       event.Message,
+			Count:         max(event.Count, 1),
+			LastTimestamp: k8s.EventTime(&event),
+		})
+	}
+	return nodes
+}
+
+func firstLabelValue(labels map[string]string, keys []string) string {
+	for _, key := range keys {
+		if value, ok := labels[key]; ok {
+			return value
+		}
+	}
+	return ""
+}
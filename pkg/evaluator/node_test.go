@@ -0,0 +1,74 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/k8s"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSnapshotNodes(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-a",
+			Labels: map[string]string{
+				corev1.LabelInstanceTypeStable: "c5.xlarge",
+				corev1.LabelTopologyZone:       "zone-b",
+				"eks.amazonaws.com/nodegroup":  "general",
+			},
+		},
+		Spec: corev1.NodeSpec{
+			Taints: []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}},
+		},
+		Status: corev1.NodeStatus{
+			NodeInfo: corev1.NodeSystemInfo{KernelVersion: "6.1.0"},
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+				{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue, Reason: "KubeletHasInsufficientMemory"},
+				{Type: corev1.NodeDiskPressure, Status: corev1.ConditionFalse},
+			},
+		},
+	}
+	newEvent := func(name string, eventType string, nodeName string, lastTimestamp time.Time) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: metav1.NamespaceDefault},
+			InvolvedObject: corev1.ObjectReference{Kind: "Node", Name: nodeName},
+			Type:           eventType,
+			Reason:         name,
+			LastTimestamp:  metav1.NewTime(lastTimestamp),
+		}
+	}
+	clientset := fake.NewClientset(
+		node,
+		newEvent("SystemOOM", corev1.EventTypeWarning, "node-a", time.Now().Add(-time.Minute)),
+		newEvent("Rebooted", corev1.EventTypeWarning, "node-a", time.Now().Add(-2*time.Hour)),
+		newEvent("NodeReady", corev1.EventTypeNormal, "node-a", time.Now()),
+		newEvent("OtherNodeOOM", corev1.EventTypeWarning, "node-b", time.Now()),
+	)
+	runner := &testRunner{
+		logger:    zap.NewNop().Sugar(),
+		k8sClient: k8s.NewFromClientset(clientset),
+	}
+
+	nodes := runner.snapshotNodes(context.Background(), &corev1.NodeList{Items: []corev1.Node{*node}})
+	snapshot := nodes["node-a"]
+	if snapshot.InstanceType != "c5.xlarge" || snapshot.Zone != "zone-b" || snapshot.NodePool != "general" ||
+		snapshot.KernelVersion != "6.1.0" {
+		t.Errorf("unexpected node metadata: %+v", snapshot)
+	}
+	if len(snapshot.Conditions) != 3 || !snapshot.Conditions[1].Unhealthy() || snapshot.Conditions[0].Unhealthy() ||
+		snapshot.Conditions[2].Unhealthy() {
+		t.Errorf("unexpected node conditions: %+v", snapshot.Conditions)
+	}
+	if len(snapshot.Taints) != 1 {
+		t.Errorf("expected 1 taint, but got %+v", snapshot.Taints)
+	}
+	if len(snapshot.WarningEvents) != 1 || snapshot.WarningEvents[0].Reason != "SystemOOM" {
+		t.Errorf("expected only the recent warning event of the node, but got %+v", snapshot.WarningEvents)
+	}
+}
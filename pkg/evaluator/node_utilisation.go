@@ -0,0 +1,150 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// NodeUtilisation is the utilisation of a whole node reported by its kubelet, which tells apart nodes which
+// are slow from nodes which are busy serving other workloads.
+type NodeUtilisation struct {
+	CPUCores                float64
+	CPUPercentage           float64
+	MemoryWorkingSetBytes   uint64
+	MemoryPercentage        float64
+	NetworkRxBytesPerSecond float64
+	NetworkTxBytesPerSecond float64
+
+	// NonEvaluatorPodCount is the number of running pods on the node which were not created by the evaluator,
+	// which is nil if the pods could not be counted
+	NonEvaluatorPodCount *int
+}
+
+type nodeStatsSample struct {
+	cpuTime                 time.Time
+	cpuUsageCoreNanoSeconds *uint64
+	memoryWorkingSetBytes   *uint64
+	networkTime             time.Time
+	networkRxBytes          *uint64
+	networkTxBytes          *uint64
+}
+
+func newNodeStatsSample(stats *k8s.NodeStats) *nodeStatsSample {
+	sample := &nodeStatsSample{}
+	if stats.CPU != nil {
+		sample.cpuTime = stats.CPU.Time.Time
+		sample.cpuUsageCoreNanoSeconds = stats.CPU.UsageCoreNanoSeconds
+	}
+	if stats.Memory != nil {
+		sample.memoryWorkingSetBytes = stats.Memory.WorkingSetBytes
+	}
+	if stats.Network != nil {
+		sample.networkTime = stats.Network.Time.Time
+		sample.networkRxBytes, sample.networkTxBytes = sumInterfaceBytes(stats.Network.Interfaces)
+	}
+	return sample
+}
+
+// newNodeUtilisation calculates the utilisation of a node between the samples. The memory working set is
+// the peak across all the samples.
+func newNodeUtilisation(samples []*nodeStatsSample, allocatable corev1.ResourceList, nonEvaluatorPodCount *int) *NodeUtilisation {
+	utilisation := &NodeUtilisation{
+		NonEvaluatorPodCount: nonEvaluatorPodCount,
+	}
+	for _, sample := range samples {
+		if sample.memoryWorkingSetBytes != nil && *sample.memoryWorkingSetBytes > utilisation.MemoryWorkingSetBytes {
+			utilisation.MemoryWorkingSetBytes = *sample.memoryWorkingSetBytes
+		}
+	}
+
+	first := samples[0]
+	last := samples[len(samples)-1]
+	cpuElapsed := last.cpuTime.Sub(first.cpuTime)
+	if first.cpuUsageCoreNanoSeconds != nil && last.cpuUsageCoreNanoSeconds != nil && cpuElapsed > 0 {
+		utilisation.CPUCores = float64(counterDelta(first.cpuUsageCoreNanoSeconds, last.cpuUsageCoreNanoSeconds)) /
+			float64(cpuElapsed.Nanoseconds())
+	}
+	networkElapsed := last.networkTime.Sub(first.networkTime)
+	if networkElapsed > 0 {
+		utilisation.NetworkRxBytesPerSecond = float64(counterDelta(first.networkRxBytes, last.networkRxBytes)) /
+			networkElapsed.Seconds()
+		utilisation.NetworkTxBytesPerSecond = float64(counterDelta(first.networkTxBytes, last.networkTxBytes)) /
+			networkElapsed.Seconds()
+	}
+
+	if cpu, ok := allocatable[corev1.ResourceCPU]; ok && cpu.MilliValue() > 0 {
+		utilisation.CPUPercentage = utilisation.CPUCores * 1000 / float64(cpu.MilliValue()) * 100
+	}
+	if memory, ok := allocatable[corev1.ResourceMemory]; ok && memory.Value() > 0 {
+		utilisation.MemoryPercentage = float64(utilisation.MemoryWorkingSetBytes) / float64(memory.Value()) * 100
+	}
+	return utilisation
+}
+
+// countNonEvaluatorPods counts the pods running on the node which were not created by the evaluator.
+func (runner *testRunner) countNonEvaluatorPods(ctx context.Context, nodeName string) (*int, error) {
+	pods, err := runner.k8sClient.ListPods(ctx, metav1.NamespaceAll, k8s.Selector{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods on node %s: %w", nodeName, err)
+	}
+	count := 0
+	for _, pod := range pods.Items {
+		// Field selectors are not supported by all clients and hence the node is checked again
+		if pod.Spec.NodeName != nodeName || pod.GetLabels()[appLabel] == appLabelValue ||
+			pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		count++
+	}
+	return &count, nil
+}
+
+// sampleNodeUtilisation captures the utilisation of the nodes before the test services are provisioned by
+// sampling the kubelets twice, one sample interval apart.
+func (runner *testRunner) sampleNodeUtilisation(ctx context.Context, nodes map[string]*Node) {
+	if runner.config.ResourceUsage.Disabled {
+		return
+	}
+	firstSamples := map[string]*nodeStatsSample{}
+	for nodeName := range nodes {
+		summary, err := runner.k8sClient.GetNodeStatsSummary(ctx, nodeName)
+		if err != nil {
+			runner.logger.Warnw("skipped collecting utilisation of node before the test", "node", nodeName, "error", err)
+			continue
+		}
+		firstSamples[nodeName] = newNodeStatsSample(&summary.Node)
+	}
+	if len(firstSamples) == 0 {
+		return
+	}
+
+	timer := time.NewTimer(runner.config.ResourceUsage.SampleInterval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return
+	case <-timer.C:
+	}
+
+	for nodeName, firstSample := range firstSamples {
+		summary, err := runner.k8sClient.GetNodeStatsSummary(ctx, nodeName)
+		if err != nil {
+			runner.logger.Warnw("skipped collecting utilisation of node before the test", "node", nodeName, "error", err)
+			continue
+		}
+		podCount, err := runner.countNonEvaluatorPods(ctx, nodeName)
+		if err != nil {
+			runner.logger.Warnw("failed to count the pods on node", "node", nodeName, "error", err)
+		}
+		nodes[nodeName].UtilisationBeforeTest = newNodeUtilisation(
+			[]*nodeStatsSample{firstSample, newNodeStatsSample(&summary.Node)}, nodes[nodeName].Allocatable, podCount)
+	}
+}
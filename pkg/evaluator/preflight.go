@@ -0,0 +1,307 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/k8s"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type PreflightStatus string
+
+const (
+	PreflightPassed  PreflightStatus = "PASS"
+	PreflightWarning PreflightStatus = "WARN"
+	PreflightFailed  PreflightStatus = "FAIL"
+)
+
+type PreflightCheck struct {
+	Name    string
+	Status  PreflightStatus
+	Message string
+}
+
+type requiredPermission struct {
+	verb        string
+	group       string
+	resource    string
+	subresource string
+	namespaced  bool
+	// namespace overrides the test services namespace of the namespaced permissions
+	namespace string
+	// optional permissions only degrade the report (e.g. resource usage is not collected) when missing
+	optional bool
+}
+
+var requiredPermissions = []requiredPermission{
+	{verb: "list", resource: "nodes"},
+	{verb: "get", resource: "namespaces"},
+	{verb: "create", resource: "namespaces"},
+	{verb: "delete", resource: "namespaces"},
+	{verb: "watch", resource: "namespaces"},
+	{verb: "create", group: "apps", resource: "deployments", namespaced: true},
+	{verb: "get", group: "apps", resource: "deployments", namespaced: true},
+	{verb: "list", resource: "pods", namespaced: true},
+	{verb: "create", resource: "services", namespaced: true},
+	{verb: "create", group: "networking.k8s.io", resource: "ingresses", namespaced: true},
+	{verb: "get", group: "networking.k8s.io", resource: "ingresses", namespaced: true},
+	// The events are listed for the provisioning failure diagnostics and the recent warning events of the nodes
+	{verb: "list", resource: "events", namespaced: true, optional: true},
+	{verb: "list", resource: "events", optional: true},
+}
+
+var resourceUsagePermissions = []requiredPermission{
+	{verb: "get", resource: "nodes", subresource: "proxy", optional: true},
+	// The pods of the other workloads on the nodes are counted across all the namespaces
+	{verb: "list", resource: "pods", optional: true},
+}
+
+var remediationPermissions = []requiredPermission{
+	{verb: "patch", resource: "nodes"},
+	{verb: "update", resource: "nodes"},
+}
+
+var watchPermissions = []requiredPermission{
+	{verb: "list", resource: "nodes"},
+	{verb: "watch", resource: "nodes"},
+	{verb: "create", resource: "events", namespace: metav1.NamespaceDefault},
+}
+
+var watchTaintPermissions = []requiredPermission{
+	{verb: "get", resource: "nodes"},
+	{verb: "patch", resource: "nodes"},
+	{verb: "update", resource: "nodes"},
+}
+
+// RunPreflightChecks validates that the test run can be performed without creating any resources.
+func (runner *testRunner) RunPreflightChecks(ctx context.Context) []*PreflightCheck {
+	checks := []*PreflightCheck{}
+	checks = append(checks, runner.checkPermissions(ctx)...)
+	checks = append(checks, runner.checkIngressClass(ctx))
+	checks = append(checks, runner.checkNamespaceOwnership(ctx))
+	checks = append(checks, runner.checkNodes(ctx)...)
+	return checks
+}
+
+func (runner *testRunner) checkPermissions(ctx context.Context) []*PreflightCheck {
+	permissions := requiredPermissions
+	if !runner.config.ResourceUsage.Disabled {
+		permissions = slices.Concat(permissions, resourceUsagePermissions)
+	}
+	if runner.config.Remediation.Enabled && runner.config.Remediation.DryRun != nil && !*runner.config.Remediation.DryRun {
+		permissions = slices.Concat(permissions, remediationPermissions)
+	}
+	return checkPermissions(ctx, runner.k8sClient, runner.namespace, permissions)
+}
+
+// RunWatchPreflightChecks validates that the new nodes can be watched, tainted and reported as events.
+func RunWatchPreflightChecks(ctx context.Context, k8sClient k8s.Interface, c *config.Config) []*PreflightCheck {
+	permissions := watchPermissions
+	if c.Watch.TaintUnevaluated {
+		permissions = slices.Concat(permissions, watchTaintPermissions)
+	}
+	return checkPermissions(ctx, k8sClient, c.Namespace, permissions)
+}
+
+func checkPermissions(ctx context.Context, k8sClient k8s.Interface, namespace string,
+	permissions []requiredPermission) []*PreflightCheck {
+	checks := []*PreflightCheck{}
+	for _, permission := range permissions {
+		resource := permission.resource
+		if permission.subresource != "" {
+			resource += "/" + permission.subresource
+		}
+		if permission.group != "" {
+			resource += "." + permission.group
+		}
+		check := &PreflightCheck{
+			Name: fmt.Sprintf("Permission to %s %s", permission.verb, resource),
+		}
+		attributes := &authorizationv1.ResourceAttributes{
+			Verb:        permission.verb,
+			Group:       permission.group,
+			Resource:    permission.resource,
+			Subresource: permission.subresource,
+		}
+		if permission.namespace != "" {
+			attributes.Namespace = permission.namespace
+			check.Name += " in namespace " + permission.namespace
+		} else if permission.namespaced {
+			attributes.Namespace = namespace
+			check.Name += " in namespace " + namespace
+		}
+
+		status, err := k8sClient.CheckAccess(ctx, attributes)
+		if err != nil {
+			check.Status = PreflightFailed
+			check.Message = fmt.Sprintf("failed to check access: %v", err)
+		} else if !status.Allowed {
+			check.Status = PreflightFailed
+			check.Message = "access denied"
+			if status.Reason != "" {
+				check.Message += ": " + status.Reason
+			}
+			if permission.optional {
+				check.Status = PreflightWarning
+			}
+		} else {
+			check.Status = PreflightPassed
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+func (runner *testRunner) checkIngressClass(ctx context.Context) *PreflightCheck {
+	check := &PreflightCheck{
+		Name: "Ingress class available",
+	}
+	className := runner.config.Ingress.ClassName
+	if className != nil && *className != "" {
+		ingressClass, err := runner.k8sClient.GetIngressClass(ctx, *className)
+		if err != nil {
+			check.Status = PreflightFailed
+			check.Message = fmt.Sprintf("failed to get ingress class %s: %v", *className, err)
+		} else if ingressClass == nil {
+			check.Status = PreflightFailed
+			check.Message = fmt.Sprintf("ingress class %s does not exist", *className)
+		} else {
+			check.Status = PreflightPassed
+			check.Message = fmt.Sprintf("ingress class %s uses controller %s", *className, ingressClass.Spec.Controller)
+		}
+		return check
+	}
+
+	ingressClasses, err := runner.k8sClient.ListIngressClasses(ctx)
+	if err != nil {
+		check.Status = PreflightFailed
+		check.Message = fmt.Sprintf("failed to list ingress classes: %v", err)
+		return check
+	}
+	for _, ingressClass := range ingressClasses.Items {
+		if ingressClass.GetAnnotations()[networkingv1.AnnotationIsDefaultIngressClass] == "true" {
+			check.Status = PreflightPassed
+			check.Message = fmt.Sprintf("using default ingress class %s", ingressClass.GetName())
+			return check
+		}
+	}
+	check.Status = PreflightWarning
+	check.Message = "no ingress class configured and no default ingress class found in the cluster"
+	return check
+}
+
+func (runner *testRunner) checkNamespaceOwnership(ctx context.Context) *PreflightCheck {
+	check := &PreflightCheck{
+		Name: "Namespace " + runner.namespace + " not owned by another party",
+	}
+	namespace, err := runner.k8sClient.GetNamespace(ctx, runner.namespace)
+	if err != nil {
+		check.Status = PreflightFailed
+		check.Message = fmt.Sprintf("failed to get namespace: %v", err)
+		return check
+	}
+	if namespace == nil {
+		check.Status = PreflightPassed
+		check.Message = "namespace does not exist and will be created"
+		return check
+	}
+	if len(namespace.GetOwnerReferences()) > 0 {
+		check.Status = PreflightFailed
+		check.Message = fmt.Sprintf("namespace is owned by %s %s", namespace.GetOwnerReferences()[0].Kind,
+			namespace.GetOwnerReferences()[0].Name)
+		return check
+	}
+	if !isOwnedNamespace(namespace) {
+		if managedBy, ok := namespace.GetLabels()[managedByLabel]; ok {
+			check.Status = PreflightFailed
+			check.Message = fmt.Sprintf("namespace is managed by %s", managedBy)
+			return check
+		}
+		if !runner.config.ForceNamespaceDeletion {
+			check.Status = PreflightFailed
+			check.Message = "namespace was not created by the evaluator (enable forceNamespaceDeletion to delete it anyway)"
+			return check
+		}
+	}
+	check.Status = PreflightPassed
+	check.Message = "existing namespace will be deleted and recreated"
+	return check
+}
+
+func (runner *testRunner) checkNodes(ctx context.Context) []*PreflightCheck {
+	nodesCheck := &PreflightCheck{
+		Name: "Nodes matching the node selector",
+	}
+	nodesList, err := runner.listNodes(ctx)
+	if err != nil {
+		nodesCheck.Status = PreflightFailed
+		nodesCheck.Message = err.Error()
+		return []*PreflightCheck{nodesCheck}
+	}
+	if len(nodesList.Items) == 0 {
+		nodesCheck.Status = PreflightFailed
+		nodesCheck.Message = "no nodes matched the node selector"
+		return []*PreflightCheck{nodesCheck}
+	}
+	nodesCheck.Status = PreflightPassed
+	nodesCheck.Message = fmt.Sprintf("%d nodes will be tested", len(nodesList.Items))
+
+	sampleNode := nodesList.Items[0]
+	imageCheck := &PreflightCheck{
+		Name: "Test service image cached on sample node " + sampleNode.GetName(),
+	}
+	if nodeHasCachedImage(&sampleNode, runner.config.TestService.Image) {
+		imageCheck.Status = PreflightPassed
+		imageCheck.Message = "image is already cached on the node"
+	} else {
+		imageCheck.Status = PreflightWarning
+		imageCheck.Message = "image is not cached on the node and will be pulled from the registry (pullability is not checked)"
+	}
+	return []*PreflightCheck{nodesCheck, imageCheck}
+}
+
+// nodeHasCachedImage checks the images reported in the node status, which is only a hint about whether the
+// image needs to be pulled and does not check that it can be pulled from the registry.
+func nodeHasCachedImage(node *corev1.Node, image string) bool {
+	for _, nodeImage := range node.Status.Images {
+		for _, name := range nodeImage.Names {
+			if name == image || strings.HasSuffix(name, "/"+image) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PreflightChecksPassed returns false if any of the checks failed.
+func PreflightChecksPassed(checks []*PreflightCheck) bool {
+	for _, check := range checks {
+		if check.Status == PreflightFailed {
+			return false
+		}
+	}
+	return true
+}
+
+// WritePreflightChecks writes the results of the preflight checks to the output as a checklist.
+func WritePreflightChecks(checks []*PreflightCheck, output io.Writer) error {
+	for _, check := range checks {
+		line := fmt.Sprintf("[%s] %s", check.Status, check.Name)
+		if check.Message != "" {
+			line += ": " + check.Message
+		}
+		_, err := fmt.Fprintln(output, line)
+		if err != nil {
+			return fmt.Errorf("failed to write preflight check: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package evaluator
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/k8s"
+	"go.uber.org/zap"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newAccessReviewClient(denied ...string) k8s.Interface {
+	clientset := fake.NewClientset()
+	clientset.PrependReactor("create", "selfsubjectaccessreviews",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+			attributes := review.Spec.ResourceAttributes
+			review.Status.Allowed = !slices.Contains(denied, attributes.Verb+" "+attributes.Resource)
+			return true, review, nil
+		})
+	return k8s.NewFromClientset(clientset)
+}
+
+func checkNames(checks []*PreflightCheck) []string {
+	names := []string{}
+	for _, check := range checks {
+		names = append(names, check.Name)
+	}
+	return names
+}
+
+func TestCheckPermissions(t *testing.T) {
+	c := newTestConfig()
+	runner := NewTestRunnerWithClients(c, zap.NewNop().Sugar(), newAccessReviewClient("list events"), nil).(*testRunner)
+	checks := runner.checkPermissions(context.Background())
+	names := checkNames(checks)
+	for _, name := range []string{"Permission to list events", "Permission to list events in namespace " + c.Namespace,
+		"Permission to list pods", "Permission to get nodes/proxy"} {
+		if !slices.Contains(names, name) {
+			t.Errorf("expected check %q, but got %v", name, names)
+		}
+	}
+	if slices.Contains(names, "Permission to patch nodes") {
+		t.Errorf("expected no remediation permissions when the remediation is a dry run, but got %v", names)
+	}
+	for _, check := range checks {
+		if check.Name == "Permission to list events" && check.Status != PreflightWarning {
+			t.Errorf("expected the missing permission to list events to be a warning, but got %s", check.Status)
+		}
+	}
+
+	c.ResourceUsage.Disabled = true
+	names = checkNames(runner.checkPermissions(context.Background()))
+	if slices.Contains(names, "Permission to list pods") || slices.Contains(names, "Permission to get nodes/proxy") {
+		t.Errorf("expected no resource usage permissions when resource usage is disabled, but got %v", names)
+	}
+}
+
+func TestRunWatchPreflightChecks(t *testing.T) {
+	c := &config.Config{}
+	checks := RunWatchPreflightChecks(context.Background(), newAccessReviewClient(), c)
+	names := checkNames(checks)
+	expected := []string{"Permission to list nodes", "Permission to watch nodes",
+		"Permission to create events in namespace default"}
+	if !slices.Equal(names, expected) {
+		t.Errorf("expected checks %v, but got %v", expected, names)
+	}
+
+	c.Watch.TaintUnevaluated = true
+	checks = RunWatchPreflightChecks(context.Background(), newAccessReviewClient("patch nodes"), c)
+	names = checkNames(checks)
+	if !slices.Contains(names, "Permission to patch nodes") || !slices.Contains(names, "Permission to update nodes") {
+		t.Errorf("expected the permissions to taint the nodes, but got %v", names)
+	}
+	if PreflightChecksPassed(checks) {
+		t.Errorf("expected the preflight checks to fail without the permission to patch nodes")
+	}
+}
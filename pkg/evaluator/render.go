@@ -0,0 +1,112 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// RenderManifests resolves the nodes to be tested and generates all the kubernetes objects which
+// would be created during a test run without creating any of them.
+func (runner *testRunner) RenderManifests(ctx context.Context) ([]runtime.Object, error) {
+	nodesList, err := runner.listNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := []runtime.Object{
+		runner.makeNamespace(runner.namespace),
+	}
+	for _, node := range nodesList.Items {
+		testService := TestService{
+			UUID:       uuid.New().String(),
+			NodeName:   node.GetName(),
+			NodeLabels: node.GetLabels(),
+		}
+		deployment, err := runner.makeDeployment(testService)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate deployment for node %s: %w", testService.NodeName, err)
+		}
+		objects = append(objects, deployment, runner.makeService(testService), runner.makeIngress(testService))
+	}
+
+	for _, object := range objects {
+		gvks, _, err := scheme.Scheme.ObjectKinds(object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve the kind of the generated object: %w", err)
+		}
+		object.GetObjectKind().SetGroupVersionKind(gvks[0])
+	}
+	return objects, nil
+}
+
+// ValidateManifests submits the objects to the API server as a server-side dry run so that they are
+// validated by the API server and the admission webhooks without being persisted. A dry run namespace is
+// not persisted either, so the namespaced objects are validated in the default namespace (with a warning)
+// if the test services namespace does not exist yet, and the namespace itself is not validated if it
+// already exists.
+func (runner *testRunner) ValidateManifests(ctx context.Context, objects []runtime.Object) error {
+	namespace, err := runner.k8sClient.GetNamespace(ctx, runner.namespace)
+	if err != nil {
+		return fmt.Errorf("failed to check if the test services namespace existed: %w", err)
+	}
+	if namespace == nil {
+		runner.logger.Warnw("test services namespace does not exist yet, validating the namespaced objects "+
+			"in the default namespace instead", "namespace", runner.namespace,
+			"validationNamespace", metav1.NamespaceDefault)
+	}
+
+	errs := []error{}
+	for _, object := range objects {
+		metaObject, err := meta.Accessor(object)
+		if err != nil {
+			return fmt.Errorf("failed to read the metadata of the generated object: %w", err)
+		}
+		kind := object.GetObjectKind().GroupVersionKind().Kind
+		if _, ok := object.(*corev1.Namespace); ok && namespace != nil {
+			runner.logger.Infow("skipped validating namespace since it already exists", "name", metaObject.GetName())
+			continue
+		}
+		if metaObject.GetNamespace() != "" && namespace == nil {
+			object = object.DeepCopyObject()
+			metaObject, err = meta.Accessor(object)
+			if err != nil {
+				return fmt.Errorf("failed to read the metadata of the generated object: %w", err)
+			}
+			metaObject.SetNamespace(metav1.NamespaceDefault)
+		}
+
+		err = runner.k8sClient.DryRunCreate(ctx, object)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %s was rejected: %w", kind, metaObject.GetName(), err))
+			continue
+		}
+		runner.logger.Infow("validated object using server-side dry run", "kind", kind, "name", metaObject.GetName(),
+			"namespace", metaObject.GetNamespace())
+	}
+	return errors.Join(errs...)
+}
+
+// WriteManifests writes the objects to the output as a multi-document YAML.
+func WriteManifests(objects []runtime.Object, output io.Writer) error {
+	for _, object := range objects {
+		b, err := yaml.Marshal(object)
+		if err != nil {
+			return fmt.Errorf("failed to convert object to yaml: %w", err)
+		}
+		_, err = fmt.Fprintf(output, "---\n%s", b)
+		if err != nil {
+			return fmt.Errorf("failed to write object to output: %w", err)
+		}
+	}
+	return nil
+}
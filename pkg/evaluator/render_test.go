@@ -0,0 +1,67 @@
+package evaluator
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/k8s"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestValidateManifests(t *testing.T) {
+	validate := func(cluster *fakeCluster) ([]string, *observer.ObservedLogs) {
+		core, logs := observer.New(zap.WarnLevel)
+		runner := NewTestRunnerWithClients(newTestConfig(), zap.New(core).Sugar(), k8s.NewFromClientset(cluster.clientset),
+			newTestServicesServer(t, cluster))
+		objects, err := runner.RenderManifests(context.Background())
+		if err != nil {
+			t.Fatalf("failed to render manifests: %v", err)
+		}
+		cluster.clientset.ClearActions()
+		err = runner.ValidateManifests(context.Background(), objects)
+		if err != nil {
+			t.Fatalf("failed to validate manifests: %v", err)
+		}
+		validated := []string{}
+		for _, action := range cluster.clientset.Actions() {
+			if createAction, ok := action.(k8stesting.CreateAction); ok {
+				validated = append(validated, createAction.GetResource().Resource+"/"+createAction.GetNamespace())
+			}
+		}
+		return validated, logs
+	}
+
+	// The namespaced objects are validated in the default namespace before the namespace is created
+	validated, logs := validate(newFakeCluster(fastNode))
+	expected := []string{"namespaces/", "deployments/default", "services/default", "ingresses/default"}
+	if !slices.Equal(validated, expected) {
+		t.Errorf("expected %v to be validated, but got %v", expected, validated)
+	}
+	if logs.FilterFieldKey("validationNamespace").Len() != 1 {
+		t.Errorf("expected a warning about validating in the default namespace, but got %v", logs.All())
+	}
+
+	cluster := newFakeCluster(fastNode)
+	_, err := cluster.clientset.CoreV1().Namespaces().Create(context.Background(), &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: newTestConfig().Namespace,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("failed to create namespace: %v", err)
+	}
+	validated, logs = validate(cluster)
+	namespace := newTestConfig().Namespace
+	expected = []string{"deployments/" + namespace, "services/" + namespace, "ingresses/" + namespace}
+	if !slices.Equal(validated, expected) {
+		t.Errorf("expected %v to be validated in the existing namespace, but got %v", expected, validated)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("expected no warnings when the namespace exists, but got %v", logs.All())
+	}
+}
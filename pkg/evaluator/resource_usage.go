@@ -0,0 +1,244 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ResourceUsage is the resource usage of a test service pod reported by the kubelet while a test was running.
+type ResourceUsage struct {
+	PodName     string
+	SampleCount int
+
+	AverageCPUCores        float64
+	CPUThrottledPercentage float64
+	CPUThrottledTime       time.Duration
+
+	PeakMemoryWorkingSetBytes uint64
+	NetworkRxBytes            uint64
+	NetworkTxBytes            uint64
+}
+
+type resourceUsageSample struct {
+	cpuTime                 time.Time
+	cpuUsageCoreNanoSeconds *uint64
+	memoryWorkingSetBytes   *uint64
+	networkRxBytes          *uint64
+	networkTxBytes          *uint64
+	cpuThrottling           *k8s.CPUThrottling
+	node                    *nodeStatsSample
+}
+
+// resourceUsageCollector samples the kubelet of a node for the resource usage of a test service pod
+// and the utilisation of the node in the background until it is stopped.
+type resourceUsageCollector struct {
+	runner               *testRunner
+	nodeName             string
+	nodeAllocatable      corev1.ResourceList
+	nonEvaluatorPodCount *int
+	pod                  *corev1.Pod
+	samples              []*resourceUsageSample
+	stopCh               chan struct{}
+	doneCh               chan struct{}
+}
+
+// startResourceUsageCollection starts sampling the resource usage of the test service pod. Nil is returned
+// if the resource usage cannot be collected, in which case the test proceeds without it.
+func (runner *testRunner) startResourceUsageCollection(ctx context.Context, testService *TestService) *resourceUsageCollector {
+	if runner.config.ResourceUsage.Disabled {
+		return nil
+	}
+	pod, err := runner.findTestServicePod(ctx, testService)
+	if err != nil {
+		runner.logger.Warnw("skipped collecting resource usage of test service", "node", testService.NodeName, "error", err)
+		return nil
+	}
+
+	nonEvaluatorPodCount, err := runner.countNonEvaluatorPods(ctx, testService.NodeName)
+	if err != nil {
+		runner.logger.Warnw("failed to count the pods on node", "node", testService.NodeName, "error", err)
+	}
+
+	collector := &resourceUsageCollector{
+		runner:               runner,
+		nodeName:             testService.NodeName,
+		nodeAllocatable:      testService.NodeAllocatable,
+		nonEvaluatorPodCount: nonEvaluatorPodCount,
+		pod:                  pod,
+		stopCh:               make(chan struct{}),
+		doneCh:               make(chan struct{}),
+	}
+	err = collector.sample(ctx)
+	if err != nil {
+		runner.logger.Warnw("skipped collecting resource usage of test service", "node", testService.NodeName,
+			"pod", pod.GetName(), "error", err)
+		return nil
+	}
+
+	go func() {
+		defer close(collector.doneCh)
+		ticker := time.NewTicker(runner.config.ResourceUsage.SampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-collector.stopCh:
+				return
+			case <-ticker.C:
+				err := collector.sample(ctx)
+				if err != nil {
+					runner.logger.Debugw("failed to sample resource usage of test service", "node", collector.nodeName,
+						"pod", collector.pod.GetName(), "error", err)
+				}
+			}
+		}
+	}()
+	return collector
+}
+
+// stop stops the background sampling and returns the resource usage of the test service pod and the
+// utilisation of the node over the samples collected.
+func (collector *resourceUsageCollector) stop(ctx context.Context) (*ResourceUsage, *NodeUtilisation) {
+	if collector == nil {
+		return nil, nil
+	}
+	close(collector.stopCh)
+	<-collector.doneCh
+
+	err := collector.sample(ctx)
+	if err != nil {
+		collector.runner.logger.Debugw("failed to sample resource usage of test service", "node", collector.nodeName,
+			"pod", collector.pod.GetName(), "error", err)
+	}
+	nodeSamples := []*nodeStatsSample{}
+	for _, sample := range collector.samples {
+		nodeSamples = append(nodeSamples, sample.node)
+	}
+	return collector.summarize(), newNodeUtilisation(nodeSamples, collector.nodeAllocatable, collector.nonEvaluatorPodCount)
+}
+
+func (runner *testRunner) findTestServicePod(ctx context.Context, testService *TestService) (*corev1.Pod, error) {
+	pods, err := runner.k8sClient.ListPods(ctx, runner.namespace, k8s.Selector{
+		LabelSelector: labels.SelectorFromSet(runner.makeLabels(*testService)).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list test service pods: %w", err)
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName == testService.NodeName && pod.Status.Phase == corev1.PodRunning {
+			return pod, nil
+		}
+	}
+	return nil, fmt.Errorf("no running test service pod found on the node")
+}
+
+func (collector *resourceUsageCollector) sample(ctx context.Context) error {
+	summary, err := collector.runner.k8sClient.GetNodeStatsSummary(ctx, collector.nodeName)
+	if err != nil {
+		return err
+	}
+	var podStats *k8s.PodStats
+	for i := range summary.Pods {
+		if summary.Pods[i].PodRef.Namespace == collector.pod.GetNamespace() && summary.Pods[i].PodRef.Name == collector.pod.GetName() {
+			podStats = &summary.Pods[i]
+			break
+		}
+	}
+	if podStats == nil {
+		return fmt.Errorf("pod %s not found in the stats summary", collector.pod.GetName())
+	}
+
+	sample := &resourceUsageSample{
+		node: newNodeStatsSample(&summary.Node),
+	}
+	if podStats.CPU != nil {
+		sample.cpuTime = podStats.CPU.Time.Time
+		sample.cpuUsageCoreNanoSeconds = podStats.CPU.UsageCoreNanoSeconds
+	}
+	if podStats.Memory != nil {
+		sample.memoryWorkingSetBytes = podStats.Memory.WorkingSetBytes
+	}
+	if podStats.Network != nil {
+		sample.networkRxBytes, sample.networkTxBytes = sumInterfaceBytes(podStats.Network.Interfaces)
+	}
+
+	throttling, err := collector.runner.k8sClient.GetNodeCPUThrottling(ctx, collector.nodeName)
+	if err != nil {
+		// Throttling is reported by cAdvisor which is not exposed by all kubelets
+		collector.runner.logger.Debugw("failed to get cpu throttling of test service", "node", collector.nodeName,
+			"pod", collector.pod.GetName(), "error", err)
+	} else {
+		sample.cpuThrottling = throttling[k8s.ContainerKey{
+			Namespace: collector.pod.GetNamespace(),
+			Pod:       collector.pod.GetName(),
+			Container: testServiceContainerName,
+		}]
+	}
+	collector.samples = append(collector.samples, sample)
+	return nil
+}
+
+func (collector *resourceUsageCollector) summarize() *ResourceUsage {
+	usage := &ResourceUsage{
+		PodName:     collector.pod.GetName(),
+		SampleCount: len(collector.samples),
+	}
+	for _, sample := range collector.samples {
+		if sample.memoryWorkingSetBytes != nil && *sample.memoryWorkingSetBytes > usage.PeakMemoryWorkingSetBytes {
+			usage.PeakMemoryWorkingSetBytes = *sample.memoryWorkingSetBytes
+		}
+	}
+
+	first := collector.samples[0]
+	last := collector.samples[len(collector.samples)-1]
+	elapsed := last.cpuTime.Sub(first.cpuTime)
+	if first.cpuUsageCoreNanoSeconds != nil && last.cpuUsageCoreNanoSeconds != nil && elapsed > 0 {
+		usage.AverageCPUCores = float64(counterDelta(first.cpuUsageCoreNanoSeconds, last.cpuUsageCoreNanoSeconds)) /
+			float64(elapsed.Nanoseconds())
+	}
+	if first.cpuThrottling != nil && last.cpuThrottling != nil {
+		periods := last.cpuThrottling.Periods - first.cpuThrottling.Periods
+		if periods > 0 {
+			usage.CPUThrottledPercentage = (last.cpuThrottling.ThrottledPeriods - first.cpuThrottling.ThrottledPeriods) / periods * 100
+		}
+		usage.CPUThrottledTime = time.Duration((last.cpuThrottling.ThrottledSeconds - first.cpuThrottling.ThrottledSeconds) *
+			float64(time.Second))
+	}
+	usage.NetworkRxBytes = counterDelta(first.networkRxBytes, last.networkRxBytes)
+	usage.NetworkTxBytes = counterDelta(first.networkTxBytes, last.networkTxBytes)
+	return usage
+}
+
+func sumInterfaceBytes(interfaces []k8s.InterfaceStats) (*uint64, *uint64) {
+	var rxBytes, txBytes *uint64
+	for _, iface := range interfaces {
+		if iface.RxBytes != nil {
+			if rxBytes == nil {
+				rxBytes = new(uint64)
+			}
+			*rxBytes += *iface.RxBytes
+		}
+		if iface.TxBytes != nil {
+			if txBytes == nil {
+				txBytes = new(uint64)
+			}
+			*txBytes += *iface.TxBytes
+		}
+	}
+	return rxBytes, txBytes
+}
+
+// counterDelta returns the increase of a cumulative counter, treating a reset (e.g. container restart) as no usage.
+func counterDelta(first *uint64, last *uint64) uint64 {
+	if first == nil || last == nil || *last < *first {
+		return 0
+	}
+	return *last - *first
+}
@@ -3,8 +3,10 @@ package evaluator
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -13,13 +15,21 @@ import (
 	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/k8s"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 type TestRunnerInterface interface {
 	RunTest(ctx context.Context) ([]*TestSuite, error)
+	RenderManifests(ctx context.Context) ([]runtime.Object, error)
+	ValidateManifests(ctx context.Context, objects []runtime.Object) error
+	RunPreflightChecks(ctx context.Context) []*PreflightCheck
+	// RunID identifies the run in the labels of the objects it creates
+	RunID() string
 }
 
 type testRunner struct {
+	runID      string
+	namespace  string
 	config     *config.Config
 	logger     *zap.SugaredLogger
 	k8sClient  k8s.Interface
@@ -27,9 +37,15 @@ type testRunner struct {
 }
 
 type TestService struct {
-	UUID     string
-	NodeName string
-	BaseURL  string
+	UUID       string
+	NodeName   string
+	NodeLabels map[string]string
+	// NodeAllocatable is used for calculating the utilisation of the node
+	NodeAllocatable corev1.ResourceList
+	BaseURL         string
+
+	// ProvisioningFailureReason is set when the test service could not be brought up on the node
+	ProvisioningFailureReason string
 }
 
 type TestSuite struct {
@@ -38,10 +54,18 @@ type TestSuite struct {
 }
 
 type Test struct {
+	// Cluster is set when multiple clusters are evaluated together
+	Cluster                  string
 	NodeName                 string
+	Node                     *Node
 	TotalRequestsCount       int
 	TotalFailedRequestsCount int
 	TotalLatency             time.Duration
+	ResourceUsage            *ResourceUsage
+	NodeUtilisation          *NodeUtilisation
+
+	ProvisioningFailed        bool
+	ProvisioningFailureReason string
 }
 
 type status string
@@ -51,6 +75,8 @@ const (
 
 	loadTestWorkerCount = 10
 	iterationCount      = 10
+
+	cleanupTimeout = 2 * time.Minute
 )
 
 type testServiceResponse struct {
@@ -58,29 +84,77 @@ type testServiceResponse struct {
 }
 
 func NewTestRunner(config *config.Config, logger *zap.SugaredLogger) (TestRunnerInterface, error) {
-	k8sClient, err := k8s.NewFromKubeConfig(config.KubeConfig)
+	k8sClient, err := k8s.NewFromOptions(ClientOptions(config, nil))
 	if err != nil {
 		return nil, err
 	}
+	return NewTestRunnerWithClients(config, logger, k8sClient, &http.Client{
+		Timeout: time.Minute,
+	}), nil
+}
+
+// ClientOptions returns the options of the kubernetes client for accessing the cluster, or the configured
+// context of the kubeconfig if the cluster is nil.
+func ClientOptions(config *config.Config, cluster *config.Cluster) k8s.ClientOptions {
+	options := k8s.ClientOptions{
+		KubeConfig:        config.KubeConfig,
+		Context:           config.Client.Context,
+		ImpersonateUser:   config.Client.Impersonate.User,
+		ImpersonateGroups: config.Client.Impersonate.Groups,
+		QPS:               config.Client.QPS,
+		Burst:             config.Client.Burst,
+		Timeout:           config.Client.Timeout,
+	}
+	if cluster != nil {
+		options.KubeConfig = cluster.KubeConfig
+		options.Context = cluster.Context
+	}
+	return options
+}
+
+// NewTestRunnerWithClients creates a test runner which uses the provided clients for accessing the
+// kubernetes cluster and the test services.
+func NewTestRunnerWithClients(config *config.Config, logger *zap.SugaredLogger, k8sClient k8s.Interface,
+	httpClient *http.Client) TestRunnerInterface {
+	return NewTestRunnerWithRunID(uuid.New().String(), config, logger, k8sClient, httpClient)
+}
+
+// NewTestRunnerWithRunID creates a test runner with the provided run ID, so that the runners of the clusters
+// evaluated together share the same run ID.
+func NewTestRunnerWithRunID(runID string, config *config.Config, logger *zap.SugaredLogger, k8sClient k8s.Interface,
+	httpClient *http.Client) TestRunnerInterface {
+	namespace := config.Namespace
+	if config.UniqueNamespacePerRun {
+		namespace = fmt.Sprintf("%s-%s", config.Namespace, runID[:8])
+	}
 	return &testRunner{
-		config:    config,
-		logger:    logger,
-		k8sClient: k8sClient,
-		httpClient: &http.Client{
-			Timeout: time.Minute,
-		},
-	}, nil
+		runID:      runID,
+		namespace:  namespace,
+		config:     config,
+		logger:     logger,
+		k8sClient:  k8sClient,
+		httpClient: httpClient,
+	}
+}
+
+func (runner *testRunner) RunID() string {
+	return runner.runID
 }
 
 func (runner *testRunner) RunTest(ctx context.Context) ([]*TestSuite, error) {
-	nodesList, err := runner.k8sClient.ListNodes(ctx, k8s.Selector{
-		LabelSelector: runner.config.NodeSelector.LabelSelector,
-		FieldSelector: runner.config.NodeSelector.FieldSelector,
-	})
+	err := runner.validatePodTemplates()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list the nodes in the cluster: %w", err)
+		return nil, fmt.Errorf("invalid test service pod template: %w", err)
 	}
 
+	nodesList, err := runner.listNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := runner.snapshotNodes(ctx, nodesList)
+	runner.sampleNodeUtilisation(ctx, nodes)
+
 	testSuites := []*TestSuite{}
 	runSuite := func(run func(ctx context.Context, testServices []*TestService) *TestSuite) error {
 		nodeNames := []string{}
@@ -92,16 +166,43 @@ func (runner *testRunner) RunTest(ctx context.Context) ([]*TestSuite, error) {
 		var testServices []*TestService
 		testServices, err = runner.prepareTestServices(ctx, nodesList)
 		defer func() {
-			err = runner.cleanupTestServices(ctx)
-			if err != nil {
-				runner.logger.Warnw("failed to cleanup test services", "namespace", runner.config.Namespace, "error", err)
+			// Cleanup should happen even if the test run was cancelled
+			cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+			defer cancel()
+			cleanupErr := runner.cleanupTestServices(cleanupCtx)
+			if cleanupErr != nil {
+				runner.logger.Warnw("failed to cleanup test services", "namespace", runner.namespace, "error", cleanupErr)
+				return
 			}
-			runner.logger.Info("cleaned up all resource", "namespace", runner.config.Namespace)
+			runner.logger.Infow("cleaned up all resource", "namespace", runner.namespace)
 		}()
 		if err != nil {
 			return err
 		}
-		testSuites = append(testSuites, run(ctx, testServices))
+
+		readyTestServices := []*TestService{}
+		failedTests := []*Test{}
+		for _, testService := range testServices {
+			if testService.ProvisioningFailureReason == "" {
+				readyTestServices = append(readyTestServices, testService)
+			} else {
+				failedTests = append(failedTests, &Test{
+					NodeName:                  testService.NodeName,
+					ProvisioningFailed:        true,
+					ProvisioningFailureReason: testService.ProvisioningFailureReason,
+				})
+			}
+		}
+		testSuite := run(ctx, readyTestServices)
+		if ctx.Err() != nil {
+			// Results of an interrupted test suite are incomplete
+			return fmt.Errorf("test suite %s was interrupted: %w", testSuite.Name, ctx.Err())
+		}
+		testSuite.Tests = append(testSuite.Tests, failedTests...)
+		for _, test := range testSuite.Tests {
+			test.Node = nodes[test.NodeName]
+		}
+		testSuites = append(testSuites, testSuite)
 		return nil
 	}
 
@@ -122,59 +223,103 @@ func (runner *testRunner) RunTest(ctx context.Context) ([]*TestSuite, error) {
 	return testSuites, nil
 }
 
+func (runner *testRunner) listNodes(ctx context.Context) (*corev1.NodeList, error) {
+	nodesList, err := runner.k8sClient.ListNodes(ctx, k8s.Selector{
+		LabelSelector: runner.config.NodeSelector.LabelSelector,
+		FieldSelector: runner.config.NodeSelector.FieldSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the nodes in the cluster: %w", err)
+	}
+	if len(runner.config.NodeSelector.Names) > 0 {
+		nodesList.Items = slices.DeleteFunc(nodesList.Items, func(node corev1.Node) bool {
+			return !slices.Contains(runner.config.NodeSelector.Names, node.GetName())
+		})
+	}
+	return nodesList, nil
+}
+
 func (runner *testRunner) prepareTestServices(ctx context.Context, nodesList *corev1.NodeList) ([]*TestService, error) {
-	namespace, err := runner.k8sClient.GetNamespace(ctx, runner.config.Namespace)
+	namespace, err := runner.k8sClient.GetNamespace(ctx, runner.namespace)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check if the test services namespace existed: %w", err)
 	}
 	if namespace != nil {
+		if !isOwnedNamespace(namespace) && !runner.config.ForceNamespaceDeletion {
+			return nil, fmt.Errorf("refusing to delete existing namespace %s since it was not created by the evaluator "+
+				"(enable forceNamespaceDeletion to delete it anyway)", namespace.GetName())
+		}
 		err = runner.k8sClient.DeleteNamespace(ctx, namespace.GetName())
 		if err != nil {
 			return nil, err
 		}
 		runner.logger.Infow("waiting for namespace deletion to complete", "namespace", namespace.GetName())
-		err = runner.k8sClient.WaitForNamespaceDeletion(ctx, namespace.GetName())
+		err = runner.k8sClient.WaitForNamespaceDeletion(ctx, namespace.GetName(), runner.config.Timeouts.NamespaceDeletion)
 		if err != nil {
 			return nil, err
 		}
 		runner.logger.Infow("deleted existing existing test services namespace", "namespace", namespace.GetName())
 	}
 
-	namespace, err = runner.k8sClient.CreateNamespace(ctx, runner.makeNamespace(runner.config.Namespace))
+	namespace, err = runner.k8sClient.CreateNamespace(ctx, runner.makeNamespace(runner.namespace))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create test services namespace: %w", err)
 	}
 	runner.logger.Infow("created test services namespace", "namespace", namespace.GetName())
 
 	testServices := []*TestService{}
-	for _, node := range nodesList.Items {
+	for i, node := range nodesList.Items {
 		nodeName := node.GetObjectMeta().GetName()
+		runner.logger.Infow("provisioning test service", "node", nodeName, "progress",
+			fmt.Sprintf("%d/%d", i+1, len(nodesList.Items)))
 		testService := &TestService{
-			UUID:     uuid.New().String(),
-			NodeName: nodeName,
+			UUID:            uuid.New().String(),
+			NodeName:        nodeName,
+			NodeLabels:      node.GetLabels(),
+			NodeAllocatable: node.Status.Allocatable,
 		}
-
-		deployment, err := runner.k8sClient.CreateDeployment(ctx, runner.makeDeployment(*testService))
+		err = runner.provisionTestService(ctx, testService)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create deployment for node %s: %w", nodeName, err)
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			testService.ProvisioningFailureReason = provisioningFailureReason(err)
+			runner.logger.Warnw("failed to provision test service, node will be reported as failed",
+				"namespace", namespace.GetName(), "node", nodeName, "error", err)
 		}
+		testServices = append(testServices, testService)
+	}
+	return testServices, nil
+}
 
-		service, err := runner.k8sClient.CreateService(ctx, runner.makeService(*testService))
-		if err != nil {
-			return nil, fmt.Errorf("failed to create service for node %s: %w", nodeName, err)
-		}
+func (runner *testRunner) provisionTestService(ctx context.Context, testService *TestService) error {
+	deployment, err := runner.makeDeployment(*testService)
+	if err != nil {
+		return fmt.Errorf("failed to generate deployment for node %s: %w", testService.NodeName, err)
+	}
+	runner.logger.Infow("waiting for deployment to become available", "node", testService.NodeName,
+		"deployment", deployment.GetName(), "timeout", runner.config.Timeouts.DeploymentReady)
+	deployment, err = runner.k8sClient.CreateDeployment(ctx, deployment, runner.config.Timeouts.DeploymentReady)
+	if err != nil {
+		return fmt.Errorf("failed to create deployment for node %s: %w", testService.NodeName, err)
+	}
 
-		ingress, err := runner.k8sClient.CreateIngress(ctx, runner.makeIngress(*testService))
-		if err != nil {
-			return nil, fmt.Errorf("failed to create ingress for node %s: %w", nodeName, err)
-		}
-		testService.BaseURL = runner.config.Ingress.ProtocolScheme + "://" + ingress.Spec.Rules[0].Host + ingress.Spec.Rules[0].HTTP.Paths[0].Path
+	service, err := runner.k8sClient.CreateService(ctx, runner.makeService(*testService))
+	if err != nil {
+		return fmt.Errorf("failed to create service for node %s: %w", testService.NodeName, err)
+	}
 
-		testServices = append(testServices, testService)
-		runner.logger.Infow("created test service", "namespace", namespace.GetName(), "node", nodeName,
-			"deployment", deployment.GetName(), "service", service.GetName(), "ingress", ingress.GetName())
+	runner.logger.Infow("waiting for ingress load balancer address", "node", testService.NodeName,
+		"ingress", makeName(*testService), "timeout", runner.config.Timeouts.IngressReady)
+	ingress, err := runner.k8sClient.CreateIngress(ctx, runner.makeIngress(*testService), runner.config.Timeouts.IngressReady)
+	if err != nil {
+		return fmt.Errorf("failed to create ingress for node %s: %w", testService.NodeName, err)
 	}
-	return testServices, nil
+	testService.BaseURL = runner.config.Ingress.ProtocolScheme + "://" + ingress.Spec.Rules[0].Host + ingress.Spec.Rules[0].HTTP.Paths[0].Path
+
+	runner.logger.Infow("created test service", "namespace", runner.namespace, "node", testService.NodeName,
+		"deployment", deployment.GetName(), "service", service.GetName(), "ingress", ingress.GetName())
+	return nil
 }
 
 func (runner *testRunner) runPingTest(ctx context.Context, testSvcs []*TestService) *TestSuite {
@@ -190,9 +335,11 @@ func (runner *testRunner) runPingTest(ctx context.Context, testSvcs []*TestServi
 		}
 		url := makeURL(testSvc.BaseURL, "ping")
 
-		for i := 0; i < iterationCount; i++ {
+		resourceUsageCollector := runner.startResourceUsageCollection(ctx, testSvc)
+		for i := 0; i < iterationCount && ctx.Err() == nil; i++ {
 			runner.runTestRequest(ctx, &url, test)
 		}
+		test.ResourceUsage, test.NodeUtilisation = resourceUsageCollector.stop(ctx)
 		testSuite.Tests = append(testSuite.Tests, test)
 	}
 	runner.logger.Infow("completed " + name)
@@ -220,7 +367,7 @@ func (runner *testRunner) runLoadTest(ctx context.Context, name string, reqPath
 				workerChannel <- -1 // Signal ready to start test
 
 				reqCount := <-workerChannel
-				for i := 0; i < reqCount; i++ {
+				for i := 0; i < reqCount && ctx.Err() == nil; i++ {
 					runner.runTestRequest(ctx, &url, &workerTest)
 				}
 				workerChannel <- -1 // Signal test completed
@@ -236,6 +383,7 @@ func (runner *testRunner) runLoadTest(ctx context.Context, name string, reqPath
 		}
 
 		// Send start reqCount
+		resourceUsageCollector := runner.startResourceUsageCollection(ctx, testSvc)
 		for _, workerChannel := range workerChannels {
 			workerChannel <- iterationCount
 		}
@@ -244,6 +392,7 @@ func (runner *testRunner) runLoadTest(ctx context.Context, name string, reqPath
 		for _, workerChannel := range workerChannels {
 			<-workerChannel
 		}
+		resourceUsage, nodeUtilisation := resourceUsageCollector.stop(ctx)
 
 		// Merge results
 		finalTest := &Test{
@@ -251,6 +400,8 @@ func (runner *testRunner) runLoadTest(ctx context.Context, name string, reqPath
 			TotalRequestsCount:       0,
 			TotalFailedRequestsCount: 0,
 			TotalLatency:             0,
+			ResourceUsage:            resourceUsage,
+			NodeUtilisation:          nodeUtilisation,
 		}
 		for _, workerChannel := range workerResultsChannels {
 			test := <-workerChannel
@@ -265,15 +416,25 @@ func (runner *testRunner) runLoadTest(ctx context.Context, name string, reqPath
 }
 
 func (runner *testRunner) runTestRequest(ctx context.Context, url *string, test *Test) {
+	test.TotalRequestsCount++
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, (*url), nil)
 	if err != nil {
-		runner.logger.Fatalw("Failed to create request", "error", err)
+		runner.logger.Errorw("Failed to create request", "url", *url, "error", err)
+		test.TotalFailedRequestsCount++
+		return
 	}
 
 	reqStartTime := time.Now()
 	resp, err := runner.httpClient.Do(req)
 	test.TotalLatency += time.Since(reqStartTime)
-	if err != nil || resp.StatusCode != 200 {
+	if err != nil {
+		test.TotalFailedRequestsCount++
+		return
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != 200 {
 		test.TotalFailedRequestsCount++
 	} else {
 		response := &testServiceResponse{}
@@ -282,11 +443,33 @@ func (runner *testRunner) runTestRequest(ctx context.Context, url *string, test
 			test.TotalFailedRequestsCount++
 		}
 	}
-	test.TotalRequestsCount++
 }
 
 func (runner *testRunner) cleanupTestServices(ctx context.Context) error {
-	return runner.k8sClient.DeleteNamespace(ctx, runner.config.Namespace)
+	namespace, err := runner.k8sClient.GetNamespace(ctx, runner.namespace)
+	if err != nil {
+		return fmt.Errorf("failed to get the test services namespace: %w", err)
+	}
+	if namespace == nil {
+		return nil
+	}
+	if namespace.GetLabels()[runIDLabel] != runner.runID {
+		runner.logger.Warnw("skipped deleting namespace since it was not created by this run", "namespace", runner.namespace)
+		return nil
+	}
+	return runner.k8sClient.DeleteNamespace(ctx, runner.namespace)
+}
+
+func isOwnedNamespace(namespace *corev1.Namespace) bool {
+	return namespace.GetLabels()[managedByLabel] == managedByValue
+}
+
+func provisioningFailureReason(err error) string {
+	var provisioningErr *k8s.ProvisioningError
+	if errors.As(err, &provisioningErr) {
+		return provisioningErr.Reason
+	}
+	return err.Error()
 }
 
 func makeURL(baseURL, path string) string {
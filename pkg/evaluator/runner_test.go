@@ -0,0 +1,324 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/k8s"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const (
+	fastNode          = "fast-node"
+	slowNode          = "slow-node"
+	failingNode       = "failing-node"
+	flakyNode         = "flaky-node"
+	unschedulableNode = "unschedulable-node"
+
+	slowNodeDelay   = 20 * time.Millisecond
+	hostnamePostfix = ".test.k8s-node-perf-evaluator.io"
+)
+
+// fakeCluster is a fake kubernetes cluster in which deployments and ingresses become ready
+// immediately, except for the deployments on the unschedulable node which stay pending.
+type fakeCluster struct {
+	clientset *fake.Clientset
+}
+
+func newFakeCluster(nodeNames ...string) *fakeCluster {
+	objects := []runtime.Object{}
+	for _, nodeName := range nodeNames {
+		objects = append(objects, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+				Labels: map[string]string{
+					hostnameLabel: nodeName,
+				},
+			},
+		})
+	}
+	cluster := &fakeCluster{
+		clientset: fake.NewClientset(objects...),
+	}
+	cluster.clientset.PrependReactor("create", "deployments", cluster.reactToDeploymentCreation)
+	cluster.clientset.PrependReactor("create", "ingresses", cluster.reactToIngressCreation)
+	return cluster
+}
+
+func (cluster *fakeCluster) reactToDeploymentCreation(action k8stesting.Action) (bool, runtime.Object, error) {
+	deployment := action.(k8stesting.CreateAction).GetObject().(*appsv1.Deployment)
+	if deployment.Spec.Template.Spec.NodeName == unschedulableNode {
+		deployment.Status.Replicas = 1
+		err := cluster.clientset.Tracker().Add(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      deployment.GetName() + "-pod",
+				Namespace: deployment.GetNamespace(),
+				Labels:    deployment.Spec.Template.GetLabels(),
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				Conditions: []corev1.PodCondition{
+					{
+						Type:    corev1.PodScheduled,
+						Status:  corev1.ConditionFalse,
+						Reason:  corev1.PodReasonUnschedulable,
+						Message: "0/5 nodes are available: 1 node(s) had untolerated taint",
+					},
+				},
+			},
+		})
+		return err != nil, nil, err
+	}
+	deployment.Status.Replicas = 1
+	deployment.Status.AvailableReplicas = 1
+	deployment.Status.UpdatedReplicas = 1
+	return false, nil, nil
+}
+
+func (cluster *fakeCluster) reactToIngressCreation(action k8stesting.Action) (bool, runtime.Object, error) {
+	ingress := action.(k8stesting.CreateAction).GetObject().(*networkingv1.Ingress)
+	ingress.Status.LoadBalancer.Ingress = []networkingv1.IngressLoadBalancerIngress{
+		{
+			IP: "127.0.0.1",
+		},
+	}
+	return false, nil, nil
+}
+
+// nodeOf resolves the node served by the test service exposed through the provided host.
+func (cluster *fakeCluster) nodeOf(host string) (string, error) {
+	ingresses, err := cluster.clientset.NetworkingV1().Ingresses(metav1.NamespaceAll).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+	for _, ingress := range ingresses.Items {
+		if ingress.Spec.Rules[0].Host == host {
+			return ingress.GetLabels()["node"], nil
+		}
+	}
+	return "", fmt.Errorf("no ingress found for host %s", host)
+}
+
+// newTestServicesServer starts a server which behaves like the test services on each of the nodes.
+func newTestServicesServer(t *testing.T, cluster *fakeCluster) *http.Client {
+	var flakyRequestsCount atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nodeName, err := cluster.nodeOf(r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch nodeName {
+		case slowNode:
+			time.Sleep(slowNodeDelay)
+		case failingNode:
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		case flakyNode:
+			if flakyRequestsCount.Add(1)%2 == 0 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		}
+		_, _ = fmt.Fprintf(w, "{\"status\":\"success\"}")
+	}))
+	t.Cleanup(server.Close)
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("failed to parse test server url: %v", err)
+	}
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			// Requests are sent to the test server while preserving the host of the test service
+			req = req.Clone(req.Context())
+			req.Host = req.URL.Host
+			req.URL.Host = serverURL.Host
+			return http.DefaultTransport.RoundTrip(req)
+		}),
+	}
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func newTestConfig() *config.Config {
+	return &config.Config{
+		Namespace: "k8s-node-perf-evaluation-services",
+		TestService: config.TestService{
+			Image: "nadunrds/k8s-node-perf-evaluator-test-service:latest",
+		},
+		Ingress: config.Ingress{
+			ProtocolScheme:  "http",
+			HostnamePostfix: hostnamePostfix,
+			PathPrefix:      "/",
+		},
+		Timeouts: config.Timeouts{
+			NamespaceDeletion: 5 * time.Second,
+			DeploymentReady:   1500 * time.Millisecond,
+			IngressReady:      5 * time.Second,
+		},
+		ResourceUsage: config.ResourceUsage{
+			SampleInterval: 100 * time.Millisecond,
+		},
+	}
+}
+
+func TestRunTest(t *testing.T) {
+	cluster := newFakeCluster(fastNode, slowNode, failingNode, flakyNode, unschedulableNode)
+	runner := NewTestRunnerWithClients(newTestConfig(), zap.NewNop().Sugar(), k8s.NewFromClientset(cluster.clientset),
+		newTestServicesServer(t, cluster))
+
+	testSuites, err := runner.RunTest(context.Background())
+	if err != nil {
+		t.Fatalf("failed to run test: %v", err)
+	}
+	if len(testSuites) != 2 {
+		t.Fatalf("expected 2 test suites, but got %d", len(testSuites))
+	}
+
+	for _, testSuite := range testSuites {
+		tests := map[string]*Test{}
+		for _, test := range testSuite.Tests {
+			tests[test.NodeName] = test
+		}
+		if len(tests) != 5 {
+			t.Fatalf("expected results for 5 nodes in %s, but got %d", testSuite.Name, len(tests))
+		}
+
+		fast := tests[fastNode]
+		if fast.TotalRequestsCount == 0 || fast.TotalFailedRequestsCount != 0 {
+			t.Errorf("expected all requests to %s to succeed in %s, but %d/%d failed", fastNode, testSuite.Name,
+				fast.TotalFailedRequestsCount, fast.TotalRequestsCount)
+		}
+
+		slow := tests[slowNode]
+		if slow.TotalFailedRequestsCount != 0 {
+			t.Errorf("expected all requests to %s to succeed in %s, but %d failed", slowNode, testSuite.Name,
+				slow.TotalFailedRequestsCount)
+		}
+		if slow.TotalLatency < slowNodeDelay*time.Duration(slow.TotalRequestsCount) {
+			t.Errorf("expected the latency of %s to include the delay in %s, but got %s for %d requests", slowNode,
+				testSuite.Name, slow.TotalLatency, slow.TotalRequestsCount)
+		}
+
+		failing := tests[failingNode]
+		if failing.TotalRequestsCount == 0 || failing.TotalFailedRequestsCount != failing.TotalRequestsCount {
+			t.Errorf("expected all requests to %s to fail in %s, but %d/%d failed", failingNode, testSuite.Name,
+				failing.TotalFailedRequestsCount, failing.TotalRequestsCount)
+		}
+
+		flaky := tests[flakyNode]
+		if flaky.TotalFailedRequestsCount == 0 || flaky.TotalFailedRequestsCount == flaky.TotalRequestsCount {
+			t.Errorf("expected some requests to %s to fail in %s, but %d/%d failed", flakyNode, testSuite.Name,
+				flaky.TotalFailedRequestsCount, flaky.TotalRequestsCount)
+		}
+
+		unschedulable := tests[unschedulableNode]
+		if !unschedulable.ProvisioningFailed {
+			t.Errorf("expected provisioning to fail for %s in %s", unschedulableNode, testSuite.Name)
+		}
+		if !strings.Contains(unschedulable.ProvisioningFailureReason, "pod pending: Unschedulable") {
+			t.Errorf("expected the provisioning failure reason of %s to explain that the pod is pending, but got %q",
+				unschedulableNode, unschedulable.ProvisioningFailureReason)
+		}
+		if unschedulable.TotalRequestsCount != 0 {
+			t.Errorf("expected no requests to be sent to %s in %s, but got %d", unschedulableNode, testSuite.Name,
+				unschedulable.TotalRequestsCount)
+		}
+	}
+
+	namespaces, err := cluster.clientset.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list namespaces: %v", err)
+	}
+	if len(namespaces.Items) != 0 {
+		t.Errorf("expected the test services namespace to be cleaned up, but found %d namespaces", len(namespaces.Items))
+	}
+}
+
+func TestRunTestRefusesToDeleteUnownedNamespace(t *testing.T) {
+	cluster := newFakeCluster(fastNode)
+	_, err := cluster.clientset.CoreV1().Namespaces().Create(context.Background(), &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: newTestConfig().Namespace,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("failed to create namespace: %v", err)
+	}
+	runner := NewTestRunnerWithClients(newTestConfig(), zap.NewNop().Sugar(), k8s.NewFromClientset(cluster.clientset),
+		newTestServicesServer(t, cluster))
+
+	_, err = runner.RunTest(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "refusing to delete existing namespace") {
+		t.Fatalf("expected the test run to refuse deleting the namespace, but got %v", err)
+	}
+	namespace, err := cluster.clientset.CoreV1().Namespaces().Get(context.Background(), newTestConfig().Namespace, metav1.GetOptions{})
+	if err != nil || namespace == nil {
+		t.Errorf("expected the unowned namespace to remain, but got %v", err)
+	}
+}
+
+func TestRunTestInvalidPodTemplate(t *testing.T) {
+	cluster := newFakeCluster(fastNode)
+	testConfig := newTestConfig()
+	testConfig.TestService.NodeOverrides = []config.NodeOverride{
+		{
+			LabelSelector: "pool=gpu",
+			PodTemplate: map[string]interface{}{
+				"spec": map[string]interface{}{"containers": "not-a-list"},
+			},
+		},
+	}
+	runner := NewTestRunnerWithClients(testConfig, zap.NewNop().Sugar(), k8s.NewFromClientset(cluster.clientset),
+		newTestServicesServer(t, cluster))
+
+	// The override does not match any node, but is still a mistake in the config
+	_, err := runner.RunTest(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "invalid test service pod template") {
+		t.Fatalf("expected the test run to fail due to the invalid pod template, but got %v", err)
+	}
+	namespaces, err := cluster.clientset.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list namespaces: %v", err)
+	}
+	if len(namespaces.Items) != 0 {
+		t.Errorf("expected nothing to be provisioned, but found %d namespaces", len(namespaces.Items))
+	}
+}
+
+func TestRunTestCancelled(t *testing.T) {
+	cluster := newFakeCluster(fastNode)
+	runner := NewTestRunnerWithClients(newTestConfig(), zap.NewNop().Sugar(), k8s.NewFromClientset(cluster.clientset),
+		newTestServicesServer(t, cluster))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	testSuites, err := runner.RunTest(ctx)
+	if err == nil {
+		t.Fatalf("expected the cancelled test run to fail")
+	}
+	if len(testSuites) != 0 {
+		t.Errorf("expected no test suites to complete, but got %d", len(testSuites))
+	}
+}
@@ -0,0 +1,140 @@
+package history
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+)
+
+// minTrendFit is the minimum coefficient of determination of the latency trend, so that a single slow run
+// is not mistaken for a gradual degradation.
+const minTrendFit = 0.5
+
+// Degradation is a gradual increase of the average latency of a node in a test suite across the runs.
+type Degradation struct {
+	NodeName string
+	Suite    string
+	Runs     int
+	// FromLatency and ToLatency are the average latencies of the first and the latest run in the trend
+	FromLatency        time.Duration
+	ToLatency          time.Duration
+	IncreasePercentage float64
+}
+
+// DetectDegradation fits a linear trend to the average latencies of the latest runs of each test suite and
+// reports the suites in which the latency increased beyond the allowed percentage over those runs. The runs
+// in which provisioning failed are ignored.
+func DetectDegradation(records []*Record, degradationConfig config.Degradation) []*Degradation {
+	latencies := map[string][]time.Duration{}
+	suites := []string{}
+	nodeName := ""
+	for _, record := range records {
+		if record.ProvisioningFailed || record.RequestCount == 0 {
+			continue
+		}
+		if _, ok := latencies[record.Suite]; !ok {
+			suites = append(suites, record.Suite)
+		}
+		latencies[record.Suite] = append(latencies[record.Suite], record.AverageLatency)
+		nodeName = record.NodeName
+	}
+	sort.Strings(suites)
+
+	degradations := []*Degradation{}
+	for _, suite := range suites {
+		suiteLatencies := latencies[suite]
+		suiteLatencies = suiteLatencies[max(0, len(suiteLatencies)-degradationConfig.Window):]
+		if len(suiteLatencies) < degradationConfig.MinRuns {
+			continue
+		}
+		intercept, slope, fit := fitTrend(suiteLatencies)
+		if slope <= 0 || intercept <= 0 || fit < minTrendFit {
+			continue
+		}
+		to := intercept + slope*float64(len(suiteLatencies)-1)
+		increasePercentage := (to - intercept) / intercept * 100
+		if increasePercentage < degradationConfig.MaxIncreasePercentage {
+			continue
+		}
+		degradations = append(degradations, &Degradation{
+			NodeName:           nodeName,
+			Suite:              suite,
+			Runs:               len(suiteLatencies),
+			FromLatency:        time.Duration(intercept),
+			ToLatency:          time.Duration(to),
+			IncreasePercentage: increasePercentage,
+		})
+	}
+	return degradations
+}
+
+// fitTrend fits a line to the latencies using least squares, and returns its intercept and slope in
+// nanoseconds and its coefficient of determination.
+func fitTrend(latencies []time.Duration) (float64, float64, float64) {
+	n := float64(len(latencies))
+	var sumX, sumY, sumXY, sumXX, sumYY float64
+	for i, latency := range latencies {
+		x := float64(i)
+		y := float64(latency.Nanoseconds())
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+		sumYY += y * y
+	}
+	covariance := n*sumXY - sumX*sumY
+	varianceX := n*sumXX - sumX*sumX
+	varianceY := n*sumYY - sumY*sumY
+	if varianceX == 0 || varianceY == 0 {
+		return sumY / n, 0, 0
+	}
+	slope := covariance / varianceX
+	intercept := (sumY - slope*sumX) / n
+	correlation := covariance / math.Sqrt(varianceX*varianceY)
+	return intercept, slope, correlation * correlation
+}
+
+// WriteNodeHistory writes the records of a node as a table, from the oldest to the newest.
+func WriteNodeHistory(records []*Record, output io.Writer) error {
+	w := tabwriter.NewWriter(output, 1, 1, 3, ' ', 0)
+	_, err := fmt.Fprintln(w, "TIME\tRUN\tSUITE\tREQUESTS\tAVERAGE LATENCY\tFAILED\t")
+	if err != nil {
+		return fmt.Errorf("failed to write header of node history: %w", err)
+	}
+	for _, record := range records {
+		if record.ProvisioningFailed {
+			_, err = fmt.Fprintf(w, "%s\t%s\t%s\t-\tprovisioning failed\t-\t\n", record.Time.Format(time.RFC3339),
+				record.RunID, record.Suite)
+		} else {
+			_, err = fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%.2f%%\t\n", record.Time.Format(time.RFC3339), record.RunID,
+				record.Suite, record.RequestCount, record.AverageLatency, record.FailedPercentage)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write record of node history: %w", err)
+		}
+	}
+	return w.Flush()
+}
+
+// WriteDegradations writes the degradations of the nodes as a table.
+func WriteDegradations(degradations []*Degradation, output io.Writer) error {
+	w := tabwriter.NewWriter(output, 1, 1, 3, ' ', 0)
+	_, err := fmt.Fprintln(w, "NODE\tSUITE\tRUNS\tAVERAGE LATENCY TREND\tINCREASE\t")
+	if err != nil {
+		return fmt.Errorf("failed to write header of degradations: %w", err)
+	}
+	for _, degradation := range degradations {
+		_, err = fmt.Fprintf(w, "%s\t%s\t%d\t%s -> %s\t%.2f%%\t\n", degradation.NodeName, degradation.Suite,
+			degradation.Runs, degradation.FromLatency.Round(time.Microsecond), degradation.ToLatency.Round(time.Microsecond),
+			degradation.IncreasePercentage)
+		if err != nil {
+			return fmt.Errorf("failed to write degradation: %w", err)
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,135 @@
+package history
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/reports"
+)
+
+var degradationConfig = config.Degradation{
+	Window:                10,
+	MinRuns:               5,
+	MaxIncreasePercentage: 20,
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	startTime := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	// Runs are saved out of order to check that the history is ordered by the time of the run
+	for _, i := range []int{2, 0, 1} {
+		err = store.Save("prod", fmt.Sprintf("run-%d", i), startTime.Add(time.Duration(i)*time.Hour), []*reports.TestSuiteResult{
+			{
+				Name: "Ping Test",
+				TestResults: []*reports.TestResult{
+					{NodeName: "node-a", RequestCount: 10, AverageLatency: time.Duration(i+1) * time.Millisecond},
+					{NodeName: "node-b", ProvisioningFailed: true},
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("failed to save run %d: %v", i, err)
+		}
+	}
+	err = store.Save("staging", "run-0", startTime, []*reports.TestSuiteResult{
+		{Name: "Ping Test", TestResults: []*reports.TestResult{{NodeName: "node-a", RequestCount: 10}}},
+	})
+	if err != nil {
+		t.Fatalf("failed to save staging run: %v", err)
+	}
+	err = store.Close()
+	if err != nil {
+		t.Fatalf("failed to close store: %v", err)
+	}
+
+	// Records are read back from the file by a new store
+	store, err = Open(path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+	records, err := store.NodeHistory("prod", "node-a")
+	if err != nil {
+		t.Fatalf("failed to read node history: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, but got %d", len(records))
+	}
+	for i, record := range records {
+		if record.RunID != fmt.Sprintf("run-%d", i) || record.AverageLatency != time.Duration(i+1)*time.Millisecond ||
+			record.Cluster != "prod" || record.Suite != "Ping Test" {
+			t.Errorf("expected record %d to be from run-%d, but got %+v", i, i, record)
+		}
+	}
+	nodes, err := store.Nodes("prod")
+	if err != nil {
+		t.Fatalf("failed to read nodes: %v", err)
+	}
+	if strings.Join(nodes, ",") != "node-a,node-b" {
+		t.Errorf("expected the nodes of the cluster, but got %v", nodes)
+	}
+	clusters, err := store.Clusters()
+	if err != nil {
+		t.Fatalf("failed to read clusters: %v", err)
+	}
+	if strings.Join(clusters, ",") != "prod,staging" {
+		t.Errorf("expected both clusters, but got %v", clusters)
+	}
+	records, err = store.NodeHistory("prod", "node-z")
+	if err != nil || len(records) != 0 {
+		t.Errorf("expected no records for an unknown node, but got %v, %v", records, err)
+	}
+}
+
+func TestDetectDegradation(t *testing.T) {
+	for name, test := range map[string]struct {
+		latencies []time.Duration
+		degrading bool
+	}{
+		"gradual increase": {
+			latencies: []time.Duration{100, 104, 109, 113, 118, 122, 128, 131},
+			degrading: true,
+		},
+		"stable": {
+			latencies: []time.Duration{100, 102, 99, 101, 100, 98, 101, 100},
+		},
+		"single slow run": {
+			latencies: []time.Duration{100, 100, 100, 300, 100, 100, 100, 100},
+		},
+		"small increase": {
+			latencies: []time.Duration{100, 101, 102, 103, 104, 105, 106, 107},
+		},
+		"too few runs": {
+			latencies: []time.Duration{100, 150, 200, 250},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			records := []*Record{{NodeName: "node-a", Suite: "Ping Test", ProvisioningFailed: true}}
+			for _, latency := range test.latencies {
+				records = append(records, &Record{
+					NodeName:       "node-a",
+					Suite:          "Ping Test",
+					RequestCount:   10,
+					AverageLatency: latency * time.Millisecond,
+				})
+			}
+			degradations := DetectDegradation(records, degradationConfig)
+			if test.degrading != (len(degradations) == 1) {
+				t.Fatalf("expected degrading to be %t, but got %+v", test.degrading, degradations)
+			}
+			if test.degrading && (degradations[0].Runs != len(test.latencies) || degradations[0].IncreasePercentage < 20) {
+				t.Errorf("expected the degradation across all the runs, but got %+v", degradations[0])
+			}
+		})
+	}
+}
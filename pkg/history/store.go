@@ -0,0 +1,231 @@
+package history
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/reports"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Record is the result of a node in a test suite of a single run.
+type Record struct {
+	Cluster            string
+	RunID              string
+	NodeName           string
+	Suite              string
+	Time               time.Time
+	RequestCount       int
+	AverageLatency     time.Duration
+	FailedPercentage   float64
+	ProvisioningFailed bool
+}
+
+// Store persists the results of the runs across the clusters.
+type Store interface {
+	Save(cluster string, runID string, runTime time.Time, testSuiteResults []*reports.TestSuiteResult) error
+	// NodeHistory returns the records of the node, ordered from the oldest to the newest
+	NodeHistory(cluster string, nodeName string) ([]*Record, error)
+	// Nodes returns the names of the nodes of the cluster with records
+	Nodes(cluster string) ([]string, error)
+	Clusters() ([]string, error)
+	Close() error
+}
+
+// boltStore keeps the records in nested buckets (cluster, node and then suite) with the keys ordered by
+// the time of the run, so that the history of a node is read without scanning the other nodes.
+type boltStore struct {
+	db *bolt.DB
+}
+
+var _ Store = (*boltStore)(nil)
+
+var resultsBucket = []byte("results")
+
+const openTimeout = 10 * time.Second
+
+// Open opens the database file, creating it if it does not exist. The file is locked until the store is closed.
+func Open(path string) (Store, error) {
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(resultsBucket)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to initialize history database %s: %w", path, err)
+	}
+	return &boltStore{db: db}, nil
+}
+
+func (s *boltStore) Save(cluster string, runID string, runTime time.Time, testSuiteResults []*reports.TestSuiteResult) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		clusterBucket, err := tx.Bucket(resultsBucket).CreateBucketIfNotExists([]byte(cluster))
+		if err != nil {
+			return err
+		}
+		for _, testSuiteResult := range testSuiteResults {
+			for _, testResult := range testSuiteResult.TestResults {
+				nodeBucket, err := clusterBucket.CreateBucketIfNotExists([]byte(testResult.NodeName))
+				if err != nil {
+					return err
+				}
+				suiteBucket, err := nodeBucket.CreateBucketIfNotExists([]byte(testSuiteResult.Name))
+				if err != nil {
+					return err
+				}
+				content, err := json.Marshal(&Record{
+					Cluster:            cluster,
+					RunID:              runID,
+					NodeName:           testResult.NodeName,
+					Suite:              testSuiteResult.Name,
+					Time:               runTime,
+					RequestCount:       testResult.RequestCount,
+					AverageLatency:     testResult.AverageLatency,
+					FailedPercentage:   testResult.FailedPercentage,
+					ProvisioningFailed: testResult.ProvisioningFailed,
+				})
+				if err != nil {
+					return err
+				}
+				err = suiteBucket.Put(recordKey(runTime, runID), content)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save run %s: %w", runID, err)
+	}
+	return nil
+}
+
+func (s *boltStore) NodeHistory(cluster string, nodeName string) ([]*Record, error) {
+	records := []*Record{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		clusterBucket := tx.Bucket(resultsBucket).Bucket([]byte(cluster))
+		if clusterBucket == nil {
+			return nil
+		}
+		nodeBucket := clusterBucket.Bucket([]byte(nodeName))
+		if nodeBucket == nil {
+			return nil
+		}
+		return nodeBucket.ForEachBucket(func(suite []byte) error {
+			return nodeBucket.Bucket(suite).ForEach(func(key, value []byte) error {
+				record := &Record{}
+				err := json.Unmarshal(value, record)
+				if err != nil {
+					return fmt.Errorf("failed to parse record %s: %w", key[8:], err)
+				}
+				records = append(records, record)
+				return nil
+			})
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history of node %s: %w", nodeName, err)
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+	return records, nil
+}
+
+func (s *boltStore) Nodes(cluster string) ([]string, error) {
+	nodes := []string{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		clusterBucket := tx.Bucket(resultsBucket).Bucket([]byte(cluster))
+		if clusterBucket == nil {
+			return nil
+		}
+		return clusterBucket.ForEachBucket(func(nodeName []byte) error {
+			nodes = append(nodes, string(nodeName))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nodes of cluster %s: %w", cluster, err)
+	}
+	return nodes, nil
+}
+
+func (s *boltStore) Clusters() ([]string, error) {
+	clusters := []string{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(resultsBucket).ForEachBucket(func(cluster []byte) error {
+			clusters = append(clusters, string(cluster))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read clusters: %w", err)
+	}
+	return clusters, nil
+}
+
+func (s *boltStore) Close() error {
+	return s.db.Close()
+}
+
+// recordKey orders the records of a suite by the time of the run
+func recordKey(runTime time.Time, runID string) []byte {
+	key := binary.BigEndian.AppendUint64(nil, uint64(runTime.UnixNano()))
+	return append(key, runID...)
+}
+
+// RunResults reads the results of the runs of the cluster from the store, ordered from the oldest to the newest.
+func RunResults(store Store, cluster string) ([][]*reports.TestSuiteResult, error) {
+	nodes, err := store.Nodes(cluster)
+	if err != nil {
+		return nil, err
+	}
+	records := []*Record{}
+	for _, node := range nodes {
+		nodeRecords, err := store.NodeHistory(cluster, node)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, nodeRecords...)
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+
+	runs := [][]*reports.TestSuiteResult{}
+	runIndexes := map[string]int{}
+	for _, record := range records {
+		runIndex, ok := runIndexes[record.RunID]
+		if !ok {
+			runIndex = len(runs)
+			runIndexes[record.RunID] = runIndex
+			runs = append(runs, []*reports.TestSuiteResult{})
+		}
+		var testSuiteResult *reports.TestSuiteResult
+		for _, existingResult := range runs[runIndex] {
+			if existingResult.Name == record.Suite {
+				testSuiteResult = existingResult
+			}
+		}
+		if testSuiteResult == nil {
+			testSuiteResult = &reports.TestSuiteResult{Name: record.Suite}
+			runs[runIndex] = append(runs[runIndex], testSuiteResult)
+		}
+		testSuiteResult.TestResults = append(testSuiteResult.TestResults, &reports.TestResult{
+			NodeName:           record.NodeName,
+			RequestCount:       record.RequestCount,
+			AverageLatency:     record.AverageLatency,
+			FailedPercentage:   record.FailedPercentage,
+			ProvisioningFailed: record.ProvisioningFailed,
+		})
+	}
+	return runs, nil
+}
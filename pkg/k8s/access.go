@@ -0,0 +1,19 @@
+package k8s
+
+import (
+	"context"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+)
+
+func (c *client) CheckAccess(ctx context.Context, attributes *authorizationv1.ResourceAttributes) (*authorizationv1.SubjectAccessReviewStatus, error) {
+	review, err := c.clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: attributes,
+		},
+	}, createOptions)
+	if err != nil {
+		return nil, err
+	}
+	return &review.Status, nil
+}
@@ -2,31 +2,96 @@ package k8s
 
 import (
 	"fmt"
+	"path/filepath"
+	"time"
 
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
 type client struct {
-	clientset *kubernetes.Clientset
+	clientset kubernetes.Interface
 }
 
 var _ Interface = (*client)(nil)
 
+// ClientOptions configures how the kubeconfig is loaded and how the client talks to the API server.
+type ClientOptions struct {
+	// KubeConfig is a list of kubeconfig files merged in the same way as the KUBECONFIG environment variable.
+	// The KUBECONFIG environment variable (or ~/.kube/config) is used if empty.
+	KubeConfig string
+	// Context of the kubeconfig to use (defaults to the current context)
+	Context           string
+	ImpersonateUser   string
+	ImpersonateGroups []string
+	// QPS and Burst limit the requests made by the client (the client-go defaults are used if zero)
+	QPS   float32
+	Burst int
+	// Timeout of a single request made to the API server (no timeout if zero)
+	Timeout time.Duration
+}
+
 func NewFromKubeConfig(kubeConfigPath string) (Interface, error) {
-	// use the current context in kubeconfig
-	config, err := clientcmd.BuildConfigFromFlags("", kubeConfigPath)
+	return NewFromOptions(ClientOptions{KubeConfig: kubeConfigPath})
+}
+
+// NewFromOptions creates a client using the kubeconfig and the client options.
+func NewFromOptions(options ClientOptions) (Interface, error) {
+	config, err := NewRESTConfig(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFromRESTConfig(config)
+}
+
+// NewRESTConfig loads the kubeconfig and applies the client options to it. Exec based auth plugins configured
+// in the kubeconfig are resolved by client-go when the requests are made.
+func NewRESTConfig(options ClientOptions) (*rest.Config, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if options.KubeConfig != "" {
+		loadingRules.Precedence = filepath.SplitList(options.KubeConfig)
+	}
+	overrides := &clientcmd.ConfigOverrides{
+		CurrentContext: options.Context,
+		AuthInfo: clientcmdapi.AuthInfo{
+			Impersonate:       options.ImpersonateUser,
+			ImpersonateGroups: options.ImpersonateGroups,
+		},
+	}
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
 	if err != nil {
+		if options.Context != "" {
+			return nil, fmt.Errorf("failed to create k8s config for context %q: %w", options.Context, err)
+		}
 		return nil, fmt.Errorf("failed to create k8s config: %w", err)
 	}
+	if options.QPS != 0 {
+		config.QPS = options.QPS
+	}
+	if options.Burst != 0 {
+		config.Burst = options.Burst
+	}
+	if options.Timeout != 0 {
+		config.Timeout = options.Timeout
+	}
+	return config, nil
+}
 
+func NewFromRESTConfig(config *rest.Config) (Interface, error) {
 	// create the clientset
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create k8s client set: %w", err)
 	}
 
+	return NewFromClientset(clientset), nil
+}
+
+func NewFromClientset(clientset kubernetes.Interface) Interface {
 	return &client{
 		clientset: clientset,
-	}, nil
+	}
 }
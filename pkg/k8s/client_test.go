@@ -0,0 +1,107 @@
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	stagingKubeConfig = `apiVersion: v1
+kind: Config
+current-context: staging
+clusters:
+  - name: staging
+    cluster:
+      server: https://staging.example.com
+contexts:
+  - name: staging
+    context:
+      cluster: staging
+      user: staging
+users:
+  - name: staging
+    user:
+      token: staging-token
+`
+	productionKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+  - name: production
+    cluster:
+      server: https://production.example.com
+contexts:
+  - name: production
+    context:
+      cluster: production
+      user: production
+users:
+  - name: production
+    user:
+      exec:
+        apiVersion: client.authentication.k8s.io/v1
+        command: aws
+        args: ["eks", "get-token", "--cluster-name", "production"]
+        interactiveMode: Never
+`
+)
+
+func TestNewRESTConfig(t *testing.T) {
+	dir := t.TempDir()
+	stagingPath := filepath.Join(dir, "staging.yaml")
+	productionPath := filepath.Join(dir, "production.yaml")
+	for path, content := range map[string]string{stagingPath: stagingKubeConfig, productionPath: productionKubeConfig} {
+		err := os.WriteFile(path, []byte(content), 0600)
+		if err != nil {
+			t.Fatalf("failed to write kubeconfig: %v", err)
+		}
+	}
+	kubeConfig := strings.Join([]string{stagingPath, productionPath}, string(filepath.ListSeparator))
+
+	config, err := NewRESTConfig(ClientOptions{KubeConfig: kubeConfig})
+	if err != nil {
+		t.Fatalf("failed to create config: %v", err)
+	}
+	if config.Host != "https://staging.example.com" || config.BearerToken != "staging-token" {
+		t.Errorf("expected the current context of the merged kubeconfigs, but got host %s", config.Host)
+	}
+
+	config, err = NewRESTConfig(ClientOptions{
+		KubeConfig:        kubeConfig,
+		Context:           "production",
+		ImpersonateUser:   "evaluator",
+		ImpersonateGroups: []string{"node-perf-evaluators"},
+		QPS:               50,
+		Burst:             100,
+		Timeout:           30 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("failed to create config for the production context: %v", err)
+	}
+	if config.Host != "https://production.example.com" {
+		t.Errorf("expected the production context, but got host %s", config.Host)
+	}
+	if config.ExecProvider == nil || config.ExecProvider.Command != "aws" {
+		t.Errorf("expected the exec auth plugin of the production user, but got %+v", config.ExecProvider)
+	}
+	if config.Impersonate.UserName != "evaluator" || len(config.Impersonate.Groups) != 1 {
+		t.Errorf("expected the evaluator user to be impersonated, but got %+v", config.Impersonate)
+	}
+	if config.QPS != 50 || config.Burst != 100 || config.Timeout != 30*time.Second {
+		t.Errorf("expected the client tuning to be applied, but got qps %v, burst %d and timeout %s",
+			config.QPS, config.Burst, config.Timeout)
+	}
+
+	_, err = NewRESTConfig(ClientOptions{KubeConfig: kubeConfig, Context: "missing"})
+	if err == nil {
+		t.Errorf("expected an error for a missing context")
+	}
+
+	t.Setenv("KUBECONFIG", productionPath)
+	config, err = NewRESTConfig(ClientOptions{Context: "production"})
+	if err != nil || config.Host != "https://production.example.com" {
+		t.Errorf("expected the kubeconfig of the KUBECONFIG environment variable, but got %v", err)
+	}
+}
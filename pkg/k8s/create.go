@@ -18,12 +18,12 @@ func (c *client) CreateNamespace(ctx context.Context, namespace *corev1.Namespac
 	return c.clientset.CoreV1().Namespaces().Create(ctx, namespace, createOptions)
 }
 
-func (c *client) CreateDeployment(ctx context.Context, deployment *appsv1.Deployment) (*appsv1.Deployment, error) {
+func (c *client) CreateDeployment(ctx context.Context, deployment *appsv1.Deployment, timeout time.Duration) (*appsv1.Deployment, error) {
 	d, err := c.clientset.AppsV1().Deployments(deployment.GetNamespace()).Create(ctx, deployment, createOptions)
 	if err != nil {
 		return nil, err
 	}
-	err = wait.PollUntilContextTimeout(ctx, time.Second, time.Minute, true, func(ctx context.Context) (bool, error) {
+	err = wait.PollUntilContextTimeout(ctx, time.Second, timeout, true, func(ctx context.Context) (bool, error) {
 		namespace := deployment.GetNamespace()
 		deploymentName := deployment.GetName()
 
@@ -42,21 +42,33 @@ func (c *client) CreateDeployment(ctx context.Context, deployment *appsv1.Deploy
 		return false, nil
 	})
 	if err != nil {
-		return nil, err
+		reason, diagnostics := c.diagnosePods(ctx, deployment.GetNamespace(), deployment.Spec.Selector)
+		return nil, &ProvisioningError{
+			Kind:        "deployment",
+			Namespace:   deployment.GetNamespace(),
+			Name:        deployment.GetName(),
+			Reason:      reason,
+			Diagnostics: diagnostics,
+			Err:         err,
+		}
 	}
 	return d, nil
 }
 
+func (c *client) CreateEvent(ctx context.Context, event *corev1.Event) (*corev1.Event, error) {
+	return c.clientset.CoreV1().Events(event.GetNamespace()).Create(ctx, event, createOptions)
+}
+
 func (c *client) CreateService(ctx context.Context, service *corev1.Service) (*corev1.Service, error) {
 	return c.clientset.CoreV1().Services(service.GetNamespace()).Create(ctx, service, createOptions)
 }
 
-func (c *client) CreateIngress(ctx context.Context, ingress *networkingv1.Ingress) (*networkingv1.Ingress, error) {
+func (c *client) CreateIngress(ctx context.Context, ingress *networkingv1.Ingress, timeout time.Duration) (*networkingv1.Ingress, error) {
 	ing, err := c.clientset.NetworkingV1().Ingresses(ingress.GetNamespace()).Create(ctx, ingress, createOptions)
 	if err != nil {
 		return nil, err
 	}
-	err = wait.PollUntilContextTimeout(ctx, time.Second, time.Minute, true, func(ctx context.Context) (bool, error) {
+	err = wait.PollUntilContextTimeout(ctx, time.Second, timeout, true, func(ctx context.Context) (bool, error) {
 		namespace := ingress.GetNamespace()
 		ingressName := ingress.GetName()
 
@@ -73,7 +85,14 @@ func (c *client) CreateIngress(ctx context.Context, ingress *networkingv1.Ingres
 		return false, nil
 	})
 	if err != nil {
-		return nil, err
+		return nil, &ProvisioningError{
+			Kind:        "ingress",
+			Namespace:   ingress.GetNamespace(),
+			Name:        ingress.GetName(),
+			Reason:      "load balancer address was not assigned",
+			Diagnostics: c.describeRecentEvents(ctx, ingress.GetNamespace(), "Ingress", ingress.GetName()),
+			Err:         err,
+		}
 	}
 	return ing, nil
 }
@@ -14,3 +14,15 @@ var deleteOptions = metav1.DeleteOptions{
 func (c *client) DeleteNamespace(ctx context.Context, name string) error {
 	return c.clientset.CoreV1().Namespaces().Delete(ctx, name, deleteOptions)
 }
+
+func (c *client) DeleteDeployment(ctx context.Context, namespace string, name string) error {
+	return c.clientset.AppsV1().Deployments(namespace).Delete(ctx, name, deleteOptions)
+}
+
+func (c *client) DeleteService(ctx context.Context, namespace string, name string) error {
+	return c.clientset.CoreV1().Services(namespace).Delete(ctx, name, deleteOptions)
+}
+
+func (c *client) DeleteIngress(ctx context.Context, namespace string, name string) error {
+	return c.clientset.NetworkingV1().Ingresses(namespace).Delete(ctx, name, deleteOptions)
+}
@@ -0,0 +1,134 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+const recentEventsCount = 5
+
+// ProvisioningError is returned when a resource never became ready. Reason holds a short
+// human readable explanation of why (e.g. pod pending, image pull error, crash loop) and
+// Diagnostics holds the details (pod phases, container states and recent events).
+type ProvisioningError struct {
+	Kind        string
+	Namespace   string
+	Name        string
+	Reason      string
+	Diagnostics []string
+	Err         error
+}
+
+func (e *ProvisioningError) Error() string {
+	msg := fmt.Sprintf("%s %s/%s did not become ready (%s): %v", e.Kind, e.Namespace, e.Name, e.Reason, e.Err)
+	if len(e.Diagnostics) > 0 {
+		msg += fmt.Sprintf(", diagnostics: [%s]", strings.Join(e.Diagnostics, "; "))
+	}
+	return msg
+}
+
+func (e *ProvisioningError) Unwrap() error {
+	return e.Err
+}
+
+func (c *client) diagnosePods(ctx context.Context, namespace string, selector *metav1.LabelSelector) (string, []string) {
+	pods, err := c.ListPods(ctx, namespace, Selector{
+		LabelSelector: metav1.FormatLabelSelector(selector),
+	})
+	if err != nil {
+		return fmt.Sprintf("failed to list pods: %v", err), nil
+	}
+	if len(pods.Items) == 0 {
+		return "no pods were created", nil
+	}
+	reasons := []string{}
+	diagnostics := []string{}
+	for _, pod := range pods.Items {
+		reasons = append(reasons, diagnosePod(&pod))
+
+		diagnostics = append(diagnostics, fmt.Sprintf("pod %s is in phase %s", pod.GetName(), pod.Status.Phase))
+		for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			if status.State.Waiting != nil {
+				diagnostics = append(diagnostics, fmt.Sprintf("container %s is waiting: %s: %s", status.Name,
+					status.State.Waiting.Reason, status.State.Waiting.Message))
+			}
+		}
+		diagnostics = append(diagnostics, c.describeRecentEvents(ctx, namespace, "Pod", pod.GetName())...)
+	}
+	return strings.Join(reasons, "; "), diagnostics
+}
+
+func diagnosePod(pod *corev1.Pod) string {
+	for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		if status.State.Waiting != nil {
+			switch status.State.Waiting.Reason {
+			case "ErrImagePull", "ImagePullBackOff", "InvalidImageName", "ErrImageNeverPull":
+				return fmt.Sprintf("image pull error: %s: %s", status.State.Waiting.Reason, status.State.Waiting.Message)
+			case "CrashLoopBackOff":
+				return fmt.Sprintf("crash loop: container %s restarted %d times: %s", status.Name, status.RestartCount,
+					status.State.Waiting.Message)
+			}
+		}
+		if status.State.Terminated != nil && status.State.Terminated.ExitCode != 0 {
+			return fmt.Sprintf("container %s terminated: %s (exit code %d)", status.Name, status.State.Terminated.Reason,
+				status.State.Terminated.ExitCode)
+		}
+	}
+	if pod.Status.Phase == corev1.PodPending {
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+				return fmt.Sprintf("pod pending: %s: %s", condition.Reason, condition.Message)
+			}
+		}
+		return "pod pending"
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionFalse {
+			return fmt.Sprintf("pod %s but not ready: %s", strings.ToLower(string(pod.Status.Phase)), condition.Message)
+		}
+	}
+	return fmt.Sprintf("pod %s", strings.ToLower(string(pod.Status.Phase)))
+}
+
+func (c *client) describeRecentEvents(ctx context.Context, namespace string, kind string, name string) []string {
+	events, err := c.ListEvents(ctx, namespace, Selector{
+		FieldSelector: fields.Set{
+			"involvedObject.kind": kind,
+			"involvedObject.name": name,
+		}.String(),
+	})
+	if err != nil {
+		return []string{fmt.Sprintf("failed to list events of %s %s: %v", strings.ToLower(kind), name, err)}
+	}
+	items := events.Items
+	sort.Slice(items, func(i, j int) bool {
+		return EventTime(&items[i]).Before(EventTime(&items[j]))
+	})
+	if len(items) > recentEventsCount {
+		items = items[len(items)-recentEventsCount:]
+	}
+	descriptions := []string{}
+	for _, event := range items {
+		descriptions = append(descriptions, fmt.Sprintf("%s %s event %s: %s (x%d)", strings.ToLower(kind), name, event.Reason,
+			event.Message, max(event.Count, 1)))
+	}
+	return descriptions
+}
+
+// EventTime returns the time at which the event last occurred, falling back to when it was first recorded.
+func EventTime(event *corev1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
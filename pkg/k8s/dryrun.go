@@ -0,0 +1,33 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var dryRunCreateOptions = metav1.CreateOptions{
+	DryRun: []string{metav1.DryRunAll},
+}
+
+func (c *client) DryRunCreate(ctx context.Context, object runtime.Object) error {
+	var err error
+	switch o := object.(type) {
+	case *corev1.Namespace:
+		_, err = c.clientset.CoreV1().Namespaces().Create(ctx, o, dryRunCreateOptions)
+	case *appsv1.Deployment:
+		_, err = c.clientset.AppsV1().Deployments(o.GetNamespace()).Create(ctx, o, dryRunCreateOptions)
+	case *corev1.Service:
+		_, err = c.clientset.CoreV1().Services(o.GetNamespace()).Create(ctx, o, dryRunCreateOptions)
+	case *networkingv1.Ingress:
+		_, err = c.clientset.NetworkingV1().Ingresses(o.GetNamespace()).Create(ctx, o, dryRunCreateOptions)
+	default:
+		return fmt.Errorf("unsupported object type for dry run: %T", object)
+	}
+	return err
+}
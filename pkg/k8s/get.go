@@ -4,6 +4,7 @@ import (
 	"context"
 
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -20,3 +21,14 @@ func (c *client) GetNamespace(ctx context.Context, name string) (*corev1.Namespa
 	}
 	return namespace, nil
 }
+
+func (c *client) GetIngressClass(ctx context.Context, name string) (*networkingv1.IngressClass, error) {
+	ingressClass, err := c.clientset.NetworkingV1().IngressClasses().Get(ctx, name, getOptions)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return ingressClass, nil
+}
@@ -2,23 +2,52 @@ package k8s
 
 import (
 	"context"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
 type Interface interface {
 	CreateNamespace(ctx context.Context, namespace *corev1.Namespace) (*corev1.Namespace, error)
-	CreateDeployment(ctx context.Context, deployment *appsv1.Deployment) (*appsv1.Deployment, error)
+	CreateDeployment(ctx context.Context, deployment *appsv1.Deployment, timeout time.Duration) (*appsv1.Deployment, error)
 	CreateService(ctx context.Context, service *corev1.Service) (*corev1.Service, error)
-	CreateIngress(ctx context.Context, ingress *networkingv1.Ingress) (*networkingv1.Ingress, error)
+	CreateIngress(ctx context.Context, ingress *networkingv1.Ingress, timeout time.Duration) (*networkingv1.Ingress, error)
+	CreateEvent(ctx context.Context, event *corev1.Event) (*corev1.Event, error)
+	DryRunCreate(ctx context.Context, object runtime.Object) error
 
 	ListNodes(ctx context.Context, selector Selector) (*corev1.NodeList, error)
+	WatchNodes(ctx context.Context, selector Selector, resourceVersion string) (watch.Interface, error)
+	ListPods(ctx context.Context, namespace string, selector Selector) (*corev1.PodList, error)
+	ListIngressClasses(ctx context.Context) (*networkingv1.IngressClassList, error)
+	ListNamespaces(ctx context.Context, selector Selector) (*corev1.NamespaceList, error)
+	ListDeployments(ctx context.Context, namespace string, selector Selector) (*appsv1.DeploymentList, error)
+	ListServices(ctx context.Context, namespace string, selector Selector) (*corev1.ServiceList, error)
+	ListIngresses(ctx context.Context, namespace string, selector Selector) (*networkingv1.IngressList, error)
+	ListEvents(ctx context.Context, namespace string, selector Selector) (*corev1.EventList, error)
 
 	GetNamespace(ctx context.Context, name string) (*corev1.Namespace, error)
+	GetIngressClass(ctx context.Context, name string) (*networkingv1.IngressClass, error)
 
 	DeleteNamespace(ctx context.Context, name string) error
+	DeleteDeployment(ctx context.Context, namespace string, name string) error
+	DeleteService(ctx context.Context, namespace string, name string) error
+	DeleteIngress(ctx context.Context, namespace string, name string) error
 
-	WaitForNamespaceDeletion(ctx context.Context, name string) error
+	LabelNode(ctx context.Context, name string, labels map[string]string) error
+	AnnotateNode(ctx context.Context, name string, annotations map[string]string) error
+	CordonNode(ctx context.Context, name string) error
+	TaintNode(ctx context.Context, name string, taints []corev1.Taint) error
+	UntaintNode(ctx context.Context, name string, taint corev1.Taint) error
+
+	WaitForNamespaceDeletion(ctx context.Context, name string, timeout time.Duration) error
+
+	GetNodeStatsSummary(ctx context.Context, nodeName string) (*StatsSummary, error)
+	GetNodeCPUThrottling(ctx context.Context, nodeName string) (map[ContainerKey]*CPUThrottling, error)
+
+	CheckAccess(ctx context.Context, attributes *authorizationv1.ResourceAttributes) (*authorizationv1.SubjectAccessReviewStatus, error)
 }
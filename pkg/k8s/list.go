@@ -3,13 +3,55 @@ package k8s
 import (
 	"context"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
 func (c *client) ListNodes(ctx context.Context, selector Selector) (*corev1.NodeList, error) {
-	return c.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{
+	return c.clientset.CoreV1().Nodes().List(ctx, toListOptions(selector))
+}
+
+// WatchNodes watches the nodes matching the selector for the changes after the resource version.
+func (c *client) WatchNodes(ctx context.Context, selector Selector, resourceVersion string) (watch.Interface, error) {
+	options := toListOptions(selector)
+	options.ResourceVersion = resourceVersion
+	return c.clientset.CoreV1().Nodes().Watch(ctx, options)
+}
+
+func (c *client) ListPods(ctx context.Context, namespace string, selector Selector) (*corev1.PodList, error) {
+	return c.clientset.CoreV1().Pods(namespace).List(ctx, toListOptions(selector))
+}
+
+func (c *client) ListIngressClasses(ctx context.Context) (*networkingv1.IngressClassList, error) {
+	return c.clientset.NetworkingV1().IngressClasses().List(ctx, metav1.ListOptions{})
+}
+
+func (c *client) ListNamespaces(ctx context.Context, selector Selector) (*corev1.NamespaceList, error) {
+	return c.clientset.CoreV1().Namespaces().List(ctx, toListOptions(selector))
+}
+
+func (c *client) ListDeployments(ctx context.Context, namespace string, selector Selector) (*appsv1.DeploymentList, error) {
+	return c.clientset.AppsV1().Deployments(namespace).List(ctx, toListOptions(selector))
+}
+
+func (c *client) ListServices(ctx context.Context, namespace string, selector Selector) (*corev1.ServiceList, error) {
+	return c.clientset.CoreV1().Services(namespace).List(ctx, toListOptions(selector))
+}
+
+func (c *client) ListIngresses(ctx context.Context, namespace string, selector Selector) (*networkingv1.IngressList, error) {
+	return c.clientset.NetworkingV1().Ingresses(namespace).List(ctx, toListOptions(selector))
+}
+
+func (c *client) ListEvents(ctx context.Context, namespace string, selector Selector) (*corev1.EventList, error) {
+	return c.clientset.CoreV1().Events(namespace).List(ctx, toListOptions(selector))
+}
+
+func toListOptions(selector Selector) metav1.ListOptions {
+	return metav1.ListOptions{
 		LabelSelector: selector.LabelSelector,
 		FieldSelector: selector.FieldSelector,
-	})
+	}
 }
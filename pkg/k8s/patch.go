@@ -0,0 +1,92 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+func (c *client) LabelNode(ctx context.Context, name string, labels map[string]string) error {
+	return c.mergePatchNode(ctx, name, map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": labels,
+		},
+	})
+}
+
+func (c *client) AnnotateNode(ctx context.Context, name string, annotations map[string]string) error {
+	return c.mergePatchNode(ctx, name, map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	})
+}
+
+func (c *client) CordonNode(ctx context.Context, name string) error {
+	return c.mergePatchNode(ctx, name, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"unschedulable": true,
+		},
+	})
+}
+
+// TaintNode adds the taints to the node, replacing any existing taints with the same key and effect.
+func (c *client) TaintNode(ctx context.Context, name string, taints []corev1.Taint) error {
+	// Taints are replaced as a whole by patches and hence the node is updated instead
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node, err := c.clientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get node %s: %w", name, err)
+		}
+		for _, taint := range taints {
+			node.Spec.Taints = setTaint(node.Spec.Taints, taint)
+		}
+		_, err = c.clientset.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// UntaintNode removes the taints of the node with the same key and effect as the provided taint.
+func (c *client) UntaintNode(ctx context.Context, name string, taint corev1.Taint) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node, err := c.clientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get node %s: %w", name, err)
+		}
+		taints := removeTaint(node.Spec.Taints, taint)
+		if len(taints) == len(node.Spec.Taints) {
+			return nil
+		}
+		node.Spec.Taints = taints
+		_, err = c.clientset.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+func (c *client) mergePatchNode(ctx context.Context, name string, patch map[string]interface{}) error {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return fmt.Errorf("failed to generate patch for node %s: %w", name, err)
+	}
+	_, err = c.clientset.CoreV1().Nodes().Patch(ctx, name, types.MergePatchType, data, metav1.PatchOptions{})
+	return err
+}
+
+func setTaint(taints []corev1.Taint, taint corev1.Taint) []corev1.Taint {
+	return append(removeTaint(taints, taint), taint)
+}
+
+func removeTaint(taints []corev1.Taint, taint corev1.Taint) []corev1.Taint {
+	updatedTaints := []corev1.Taint{}
+	for _, existingTaint := range taints {
+		if existingTaint.Key != taint.Key || existingTaint.Effect != taint.Effect {
+			updatedTaints = append(updatedTaints, existingTaint)
+		}
+	}
+	return updatedTaints
+}
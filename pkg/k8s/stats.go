@@ -0,0 +1,186 @@
+package k8s
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// StatsSummary is the subset of the kubelet Summary API (/stats/summary) used by the evaluator.
+type StatsSummary struct {
+	Node NodeStats  `json:"node"`
+	Pods []PodStats `json:"pods"`
+}
+
+type NodeStats struct {
+	NodeName string        `json:"nodeName"`
+	CPU      *CPUStats     `json:"cpu,omitempty"`
+	Memory   *MemoryStats  `json:"memory,omitempty"`
+	Network  *NetworkStats `json:"network,omitempty"`
+}
+
+type PodStats struct {
+	PodRef  PodReference  `json:"podRef"`
+	CPU     *CPUStats     `json:"cpu,omitempty"`
+	Memory  *MemoryStats  `json:"memory,omitempty"`
+	Network *NetworkStats `json:"network,omitempty"`
+}
+
+type PodReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	UID       string `json:"uid"`
+}
+
+type CPUStats struct {
+	Time                 metav1.Time `json:"time"`
+	UsageNanoCores       *uint64     `json:"usageNanoCores,omitempty"`
+	UsageCoreNanoSeconds *uint64     `json:"usageCoreNanoSeconds,omitempty"`
+}
+
+type MemoryStats struct {
+	Time            metav1.Time `json:"time"`
+	AvailableBytes  *uint64     `json:"availableBytes,omitempty"`
+	UsageBytes      *uint64     `json:"usageBytes,omitempty"`
+	WorkingSetBytes *uint64     `json:"workingSetBytes,omitempty"`
+}
+
+type NetworkStats struct {
+	Time       metav1.Time      `json:"time"`
+	Interfaces []InterfaceStats `json:"interfaces,omitempty"`
+}
+
+type InterfaceStats struct {
+	Name    string  `json:"name"`
+	RxBytes *uint64 `json:"rxBytes,omitempty"`
+	TxBytes *uint64 `json:"txBytes,omitempty"`
+}
+
+// CPUThrottling holds the CFS throttling counters of a container reported by cAdvisor.
+type CPUThrottling struct {
+	Periods          float64
+	ThrottledPeriods float64
+	ThrottledSeconds float64
+}
+
+// ContainerKey identifies a container in the cAdvisor metrics.
+type ContainerKey struct {
+	Namespace string
+	Pod       string
+	Container string
+}
+
+func (c *client) GetNodeStatsSummary(ctx context.Context, nodeName string) (*StatsSummary, error) {
+	b, err := c.proxyToNode(ctx, nodeName, "stats", "summary")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats summary of node %s: %w", nodeName, err)
+	}
+	summary := &StatsSummary{}
+	err = json.Unmarshal(b, summary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse stats summary of node %s: %w", nodeName, err)
+	}
+	return summary, nil
+}
+
+func (c *client) GetNodeCPUThrottling(ctx context.Context, nodeName string) (map[ContainerKey]*CPUThrottling, error) {
+	b, err := c.proxyToNode(ctx, nodeName, "metrics", "cadvisor")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cadvisor metrics of node %s: %w", nodeName, err)
+	}
+	return parseCPUThrottling(b)
+}
+
+// proxyToNode sends a GET request to the kubelet of the node through the API server node proxy.
+func (c *client) proxyToNode(ctx context.Context, nodeName string, path ...string) ([]byte, error) {
+	restClient := c.clientset.CoreV1().RESTClient()
+	if r, ok := restClient.(*rest.RESTClient); !ok || r == nil {
+		return nil, fmt.Errorf("node proxy is not supported by the client")
+	}
+	return restClient.Get().
+		AbsPath(append([]string{"/api/v1/nodes", nodeName, "proxy"}, path...)...).
+		DoRaw(ctx)
+}
+
+var cpuThrottlingMetrics = map[string]func(throttling *CPUThrottling, value float64){
+	"container_cpu_cfs_periods_total": func(throttling *CPUThrottling, value float64) {
+		throttling.Periods = value
+	},
+	"container_cpu_cfs_throttled_periods_total": func(throttling *CPUThrottling, value float64) {
+		throttling.ThrottledPeriods = value
+	},
+	"container_cpu_cfs_throttled_seconds_total": func(throttling *CPUThrottling, value float64) {
+		throttling.ThrottledSeconds = value
+	},
+}
+
+// parseCPUThrottling extracts the CFS throttling counters from metrics in the Prometheus text format.
+func parseCPUThrottling(metrics []byte) (map[ContainerKey]*CPUThrottling, error) {
+	throttling := map[ContainerKey]*CPUThrottling{}
+	scanner := bufio.NewScanner(bytes.NewReader(metrics))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		labelsStart := strings.IndexByte(line, '{')
+		labelsEnd := strings.LastIndexByte(line, '}')
+		if strings.HasPrefix(line, "#") || labelsStart < 0 || labelsEnd < labelsStart {
+			continue
+		}
+		setValue, ok := cpuThrottlingMetrics[line[:labelsStart]]
+		if !ok {
+			continue
+		}
+		labels := parseMetricLabels(line[labelsStart+1 : labelsEnd])
+		fields := strings.Fields(line[labelsEnd+1:])
+		if len(fields) == 0 {
+			continue
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse value of metric %s: %w", line[:labelsStart], err)
+		}
+		key := ContainerKey{
+			Namespace: labels["namespace"],
+			Pod:       labels["pod"],
+			Container: labels["container"],
+		}
+		if _, ok := throttling[key]; !ok {
+			throttling[key] = &CPUThrottling{}
+		}
+		setValue(throttling[key], value)
+	}
+	return throttling, scanner.Err()
+}
+
+func parseMetricLabels(s string) map[string]string {
+	labels := map[string]string{}
+	for len(s) > 0 {
+		separator := strings.Index(s, "=\"")
+		if separator < 0 {
+			break
+		}
+		name := strings.TrimSpace(strings.TrimPrefix(s[:separator], ","))
+		s = s[separator+2:]
+		value := strings.Builder{}
+		i := 0
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+			}
+			value.WriteByte(s[i])
+		}
+		labels[name] = value.String()
+		if i >= len(s) {
+			break
+		}
+		s = s[i+1:]
+	}
+	return labels
+}
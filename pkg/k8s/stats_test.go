@@ -0,0 +1,38 @@
+package k8s
+
+import (
+	"testing"
+)
+
+func TestParseCPUThrottling(t *testing.T) {
+	metrics := `# HELP container_cpu_cfs_periods_total Number of elapsed enforcement period intervals.
+# TYPE container_cpu_cfs_periods_total counter
+container_cpu_cfs_periods_total{container="test-service",id="/kubepods/pod1",image="test",name="abc",namespace="perf",pod="svc-1"} 200 1700000000000
+container_cpu_cfs_periods_total{container="other",namespace="perf",pod="svc-1"} 50
+container_cpu_cfs_throttled_periods_total{container="test-service",namespace="perf",pod="svc-1"} 20
+container_cpu_cfs_throttled_seconds_total{container="test-service",namespace="perf",pod="svc-1"} 1.5
+container_cpu_usage_seconds_total{container="test-service",namespace="perf",pod="svc-1"} 99
+container_cpu_cfs_periods_total{container="test-service",namespace="perf",pod="quoted \"pod\""} 7
+`
+	throttling, err := parseCPUThrottling([]byte(metrics))
+	if err != nil {
+		t.Fatalf("failed to parse cpu throttling: %v", err)
+	}
+
+	got := throttling[ContainerKey{Namespace: "perf", Pod: "svc-1", Container: "test-service"}]
+	want := CPUThrottling{Periods: 200, ThrottledPeriods: 20, ThrottledSeconds: 1.5}
+	if got == nil || *got != want {
+		t.Errorf("expected throttling %+v, but got %+v", want, got)
+	}
+	other := throttling[ContainerKey{Namespace: "perf", Pod: "svc-1", Container: "other"}]
+	if other == nil || other.Periods != 50 {
+		t.Errorf("expected throttling of the other container to be parsed, but got %+v", other)
+	}
+	quoted := throttling[ContainerKey{Namespace: "perf", Pod: `quoted "pod"`, Container: "test-service"}]
+	if quoted == nil || quoted.Periods != 7 {
+		t.Errorf("expected escaped label values to be parsed, but got %+v", quoted)
+	}
+	if len(throttling) != 3 {
+		t.Errorf("expected throttling of 3 containers, but got %d", len(throttling))
+	}
+}
@@ -3,25 +3,131 @@ package k8s
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/watch"
 )
 
-func (c *client) WaitForNamespaceDeletion(ctx context.Context, name string) error {
-	w, err := c.clientset.CoreV1().Namespaces().Watch(ctx, metav1.ListOptions{})
-	if err != nil {
-		return err
+const diagnosticsTimeout = 10 * time.Second
+
+// NamespaceDeletionTimeoutError is returned when a namespace was not deleted within the timeout. It
+// contains the finalizers and the namespace conditions explaining what was blocking the deletion.
+type NamespaceDeletionTimeoutError struct {
+	Name       string
+	Timeout    time.Duration
+	Finalizers []string
+	Conditions []string
+}
+
+func (e *NamespaceDeletionTimeoutError) Error() string {
+	msg := fmt.Sprintf("timed out after %s waiting for namespace %s to be deleted", e.Timeout, e.Name)
+	if len(e.Finalizers) > 0 {
+		msg += fmt.Sprintf(", remaining finalizers: [%s]", strings.Join(e.Finalizers, ", "))
 	}
+	if len(e.Conditions) > 0 {
+		msg += fmt.Sprintf(", conditions: [%s]", strings.Join(e.Conditions, "; "))
+	}
+	return msg
+}
+
+func (c *client) WaitForNamespaceDeletion(ctx context.Context, name string, timeout time.Duration) error {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", name).String()
+	for {
+		namespaces, err := c.clientset.CoreV1().Namespaces().List(waitCtx, metav1.ListOptions{
+			FieldSelector: fieldSelector,
+		})
+		if err != nil {
+			if ctx.Err() == nil && waitCtx.Err() != nil {
+				return c.namespaceDeletionTimeoutError(ctx, name, timeout)
+			}
+			return fmt.Errorf("failed to get namespace %s: %w", name, err)
+		}
+		if !containsNamespace(namespaces, name) {
+			return nil
+		}
+
+		w, err := c.clientset.CoreV1().Namespaces().Watch(waitCtx, metav1.ListOptions{
+			FieldSelector:   fieldSelector,
+			ResourceVersion: namespaces.GetResourceVersion(),
+		})
+		if err != nil {
+			if ctx.Err() == nil && waitCtx.Err() != nil {
+				return c.namespaceDeletionTimeoutError(ctx, name, timeout)
+			}
+			return fmt.Errorf("failed to watch namespace %s: %w", name, err)
+		}
+		deleted := waitForDeletedEvent(waitCtx, w, name)
+		w.Stop()
+		if deleted {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if waitCtx.Err() != nil {
+			return c.namespaceDeletionTimeoutError(ctx, name, timeout)
+		}
+		// The watch was closed or expired, and therefore the namespace is listed again before re-watching
+	}
+}
+
+func waitForDeletedEvent(ctx context.Context, w watch.Interface, name string) bool {
 	for {
 		select {
-		case event := <-w.ResultChan():
-			if event.Type == watch.Deleted {
-				return nil
+		case <-ctx.Done():
+			return false
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return false
+			}
+			switch event.Type {
+			case watch.Deleted:
+				if namespace, isNamespace := event.Object.(*corev1.Namespace); isNamespace && namespace.GetName() == name {
+					return true
+				}
+			case watch.Error:
+				return false
 			}
-		case <-time.After(30 * time.Second):
-			return fmt.Errorf("timed out waiting for namespace deletion: %v", name)
 		}
 	}
 }
+
+func containsNamespace(namespaces *corev1.NamespaceList, name string) bool {
+	for _, namespace := range namespaces.Items {
+		if namespace.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *client) namespaceDeletionTimeoutError(ctx context.Context, name string, timeout time.Duration) error {
+	timeoutErr := &NamespaceDeletionTimeoutError{
+		Name:    name,
+		Timeout: timeout,
+	}
+
+	diagnosticsCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), diagnosticsTimeout)
+	defer cancel()
+	namespace, err := c.GetNamespace(diagnosticsCtx, name)
+	if err != nil || namespace == nil {
+		return timeoutErr
+	}
+	timeoutErr.Finalizers = append(timeoutErr.Finalizers, namespace.GetFinalizers()...)
+	for _, finalizer := range namespace.Spec.Finalizers {
+		timeoutErr.Finalizers = append(timeoutErr.Finalizers, string(finalizer))
+	}
+	for _, condition := range namespace.Status.Conditions {
+		if condition.Status == corev1.ConditionTrue {
+			timeoutErr.Conditions = append(timeoutErr.Conditions, fmt.Sprintf("%s: %s", condition.Type, condition.Message))
+		}
+	}
+	return timeoutErr
+}
@@ -0,0 +1,147 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWaitForNamespaceDeletion(t *testing.T) {
+	clientset := fake.NewClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "target"}},
+	)
+	client := NewFromClientset(clientset)
+	ctx := context.Background()
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = clientset.CoreV1().Namespaces().Delete(ctx, "other", metav1.DeleteOptions{})
+		time.Sleep(100 * time.Millisecond)
+		_ = clientset.CoreV1().Namespaces().Delete(ctx, "target", metav1.DeleteOptions{})
+	}()
+
+	err := client.WaitForNamespaceDeletion(ctx, "target", 5*time.Second)
+	if err != nil {
+		t.Fatalf("expected namespace deletion to complete, but got %v", err)
+	}
+	_, err = clientset.CoreV1().Namespaces().Get(ctx, "target", metav1.GetOptions{})
+	if err == nil {
+		t.Errorf("expected the wait to complete only after the target namespace was deleted")
+	}
+}
+
+func TestWaitForNamespaceDeletionAlreadyDeleted(t *testing.T) {
+	client := NewFromClientset(fake.NewClientset())
+
+	err := client.WaitForNamespaceDeletion(context.Background(), "target", time.Second)
+	if err != nil {
+		t.Fatalf("expected no error for a namespace which does not exist, but got %v", err)
+	}
+}
+
+func TestWaitForNamespaceDeletionTimeout(t *testing.T) {
+	client := NewFromClientset(fake.NewClientset(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "target",
+			Finalizers: []string{"example.com/cleanup"},
+		},
+		Status: corev1.NamespaceStatus{
+			Phase: corev1.NamespaceTerminating,
+			Conditions: []corev1.NamespaceCondition{
+				{
+					Type:    corev1.NamespaceContentRemaining,
+					Status:  corev1.ConditionTrue,
+					Message: "Some resources are remaining: ingresses.networking.k8s.io has 1 resource instances",
+				},
+			},
+		},
+	}))
+
+	err := client.WaitForNamespaceDeletion(context.Background(), "target", 200*time.Millisecond)
+	var timeoutErr *NamespaceDeletionTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected a namespace deletion timeout error, but got %v", err)
+	}
+	if len(timeoutErr.Finalizers) != 1 || timeoutErr.Finalizers[0] != "example.com/cleanup" {
+		t.Errorf("expected the remaining finalizers to be reported, but got %v", timeoutErr.Finalizers)
+	}
+	if !strings.Contains(err.Error(), "ingresses.networking.k8s.io has 1 resource instances") {
+		t.Errorf("expected the remaining resources to be reported, but got %v", err)
+	}
+}
+
+func TestDiagnosePod(t *testing.T) {
+	tests := []struct {
+		name     string
+		pod      *corev1.Pod
+		expected string
+	}{
+		{
+			name: "image pull error",
+			pod: &corev1.Pod{
+				Status: corev1.PodStatus{
+					Phase: corev1.PodPending,
+					ContainerStatuses: []corev1.ContainerStatus{
+						{
+							Name: "test-service",
+							State: corev1.ContainerState{
+								Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "not found"},
+							},
+						},
+					},
+				},
+			},
+			expected: "image pull error: ImagePullBackOff: not found",
+		},
+		{
+			name: "crash loop",
+			pod: &corev1.Pod{
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					ContainerStatuses: []corev1.ContainerStatus{
+						{
+							Name:         "test-service",
+							RestartCount: 3,
+							State: corev1.ContainerState{
+								Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off"},
+							},
+						},
+					},
+				},
+			},
+			expected: "crash loop: container test-service restarted 3 times: back-off",
+		},
+		{
+			name: "unschedulable",
+			pod: &corev1.Pod{
+				Status: corev1.PodStatus{
+					Phase: corev1.PodPending,
+					Conditions: []corev1.PodCondition{
+						{
+							Type:    corev1.PodScheduled,
+							Status:  corev1.ConditionFalse,
+							Reason:  corev1.PodReasonUnschedulable,
+							Message: "insufficient cpu",
+						},
+					},
+				},
+			},
+			expected: "pod pending: Unschedulable: insufficient cpu",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reason := diagnosePod(test.pod)
+			if reason != test.expected {
+				t.Errorf("expected reason %q, but got %q", test.expected, reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package remediation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/k8s"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/reports"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	EvaluationAnnotation  = "k8s-node-perf-evaluator/evaluation"
+	EvaluatedAtAnnotation = "k8s-node-perf-evaluator/evaluated-at"
+)
+
+type ActionType string
+
+const (
+	ActionLabel    ActionType = "label"
+	ActionTaint    ActionType = "taint"
+	ActionCordon   ActionType = "cordon"
+	ActionAnnotate ActionType = "annotate"
+)
+
+type RemediatorInterface interface {
+	Plan(evaluations []*reports.NodeEvaluation) *Plan
+	Apply(ctx context.Context, plan *Plan) error
+}
+
+// Plan is the set of actions to be taken on the nodes which failed the thresholds.
+type Plan struct {
+	Actions []*Action
+	// SkippedNodes are the failed nodes (qualified by their cluster) which were not actioned since the cap on
+	// the number of nodes was reached
+	SkippedNodes []string
+}
+
+type Action struct {
+	// Cluster of the node, which is empty when only the current context of the kubeconfig is evaluated
+	Cluster  string
+	NodeName string
+	Type     ActionType
+	Details  string
+
+	labels      map[string]string
+	taints      []corev1.Taint
+	annotations map[string]string
+}
+
+type remediator struct {
+	logger *zap.SugaredLogger
+	// k8sClients are the clients of the clusters of the nodes
+	k8sClients map[string]k8s.Interface
+	config     config.Remediation
+	now        func() time.Time
+}
+
+func NewRemediator(k8sClient k8s.Interface, config config.Remediation, logger *zap.SugaredLogger) RemediatorInterface {
+	return NewMultiClusterRemediator(map[string]k8s.Interface{"": k8sClient}, config, logger)
+}
+
+// NewMultiClusterRemediator creates a remediator which takes the actions on the nodes using the client of
+// the cluster of each node.
+func NewMultiClusterRemediator(k8sClients map[string]k8s.Interface, config config.Remediation,
+	logger *zap.SugaredLogger) RemediatorInterface {
+	return &remediator{
+		logger:     logger,
+		k8sClients: k8sClients,
+		config:     config,
+		now:        time.Now,
+	}
+}
+
+// Plan generates the configured actions for the nodes which failed the thresholds, up to the maximum
+// number of nodes allowed to be actioned in a single run across all the clusters.
+func (r *remediator) Plan(evaluations []*reports.NodeEvaluation) *Plan {
+	plan := &Plan{
+		Actions:      []*Action{},
+		SkippedNodes: []string{},
+	}
+	actionedNodes := 0
+	for _, evaluation := range evaluations {
+		if evaluation.Passed {
+			continue
+		}
+		if actionedNodes >= r.config.MaxNodes {
+			plan.SkippedNodes = append(plan.SkippedNodes, reports.QualifiedNodeName(evaluation.Cluster, evaluation.NodeName))
+			continue
+		}
+		actionedNodes++
+
+		if len(r.config.Labels) > 0 {
+			plan.Actions = append(plan.Actions, &Action{
+				Cluster:  evaluation.Cluster,
+				NodeName: evaluation.NodeName,
+				Type:     ActionLabel,
+				Details:  formatMap(r.config.Labels),
+				labels:   r.config.Labels,
+			})
+		}
+		if len(r.config.Taints) > 0 {
+			taints := []corev1.Taint{}
+			taintStrings := []string{}
+			for _, t := range r.config.Taints {
+				taint := corev1.Taint{
+					Key:    t.Key,
+					Value:  t.Value,
+					Effect: corev1.TaintEffect(t.Effect),
+				}
+				taints = append(taints, taint)
+				taintStrings = append(taintStrings, taint.ToString())
+			}
+			plan.Actions = append(plan.Actions, &Action{
+				Cluster:  evaluation.Cluster,
+				NodeName: evaluation.NodeName,
+				Type:     ActionTaint,
+				Details:  strings.Join(taintStrings, ", "),
+				taints:   taints,
+			})
+		}
+		if r.config.Cordon {
+			plan.Actions = append(plan.Actions, &Action{
+				Cluster:  evaluation.Cluster,
+				NodeName: evaluation.NodeName,
+				Type:     ActionCordon,
+				Details:  "mark unschedulable",
+			})
+		}
+		if r.config.Annotate {
+			summary := "failed: " + strings.Join(evaluation.Violations, "; ")
+			plan.Actions = append(plan.Actions, &Action{
+				Cluster:  evaluation.Cluster,
+				NodeName: evaluation.NodeName,
+				Type:     ActionAnnotate,
+				Details:  summary,
+				annotations: map[string]string{
+					EvaluationAnnotation:  summary,
+					EvaluatedAtAnnotation: r.now().UTC().Format(time.RFC3339),
+				},
+			})
+		}
+	}
+	return plan
+}
+
+// Apply takes the actions in the plan. All the actions are attempted even if some of them fail.
+func (r *remediator) Apply(ctx context.Context, plan *Plan) error {
+	errs := []error{}
+	for _, action := range plan.Actions {
+		nodeName := reports.QualifiedNodeName(action.Cluster, action.NodeName)
+		k8sClient, ok := r.k8sClients[action.Cluster]
+		var err error
+		switch {
+		case !ok:
+			err = fmt.Errorf("no client for cluster %s", action.Cluster)
+		case action.Type == ActionLabel:
+			err = k8sClient.LabelNode(ctx, action.NodeName, action.labels)
+		case action.Type == ActionTaint:
+			err = k8sClient.TaintNode(ctx, action.NodeName, action.taints)
+		case action.Type == ActionCordon:
+			err = k8sClient.CordonNode(ctx, action.NodeName)
+		case action.Type == ActionAnnotate:
+			err = k8sClient.AnnotateNode(ctx, action.NodeName, action.annotations)
+		default:
+			err = fmt.Errorf("unknown action %s", action.Type)
+		}
+		if err != nil {
+			r.logger.Warnw("failed to remediate node", "node", nodeName, "action", action.Type, "error", err)
+			errs = append(errs, fmt.Errorf("failed to %s node %s: %w", action.Type, nodeName, err))
+			continue
+		}
+		r.logger.Infow("remediated node", "node", nodeName, "action", action.Type, "details", action.Details)
+	}
+	return errors.Join(errs...)
+}
+
+// WritePlan writes the actions in the plan and the nodes skipped due to the cap as a table.
+func WritePlan(plan *Plan, output io.Writer) error {
+	w := tabwriter.NewWriter(output, 1, 1, 3, ' ', 0)
+	_, err := fmt.Fprintln(w, "NODE\tACTION\tDETAILS\t")
+	if err != nil {
+		return fmt.Errorf("failed to write header of remediation plan: %w", err)
+	}
+	for _, action := range plan.Actions {
+		_, err = fmt.Fprintf(w, "%s\t%s\t%s\t\n", reports.QualifiedNodeName(action.Cluster, action.NodeName), action.Type,
+			action.Details)
+		if err != nil {
+			return fmt.Errorf("failed to write action of remediation plan: %w", err)
+		}
+	}
+	for _, nodeName := range plan.SkippedNodes {
+		_, err = fmt.Fprintf(w, "%s\t-\tskipped since the maximum number of nodes to remediate was reached\t\n", nodeName)
+		if err != nil {
+			return fmt.Errorf("failed to write skipped node of remediation plan: %w", err)
+		}
+	}
+	return w.Flush()
+}
+
+func formatMap(m map[string]string) string {
+	pairs := []string{}
+	for key, value := range m {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
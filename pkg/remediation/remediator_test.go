@@ -0,0 +1,160 @@
+package remediation
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/k8s"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/reports"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRemediator(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clientset := fake.NewClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-b"},
+			Spec: corev1.NodeSpec{
+				Taints: []corev1.Taint{
+					{Key: "perf", Value: "unknown", Effect: corev1.TaintEffectNoSchedule},
+					{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+				},
+			},
+		},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-c"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-d"}},
+	)
+	r := &remediator{
+		logger:     zap.NewNop().Sugar(),
+		k8sClients: map[string]k8s.Interface{"": k8s.NewFromClientset(clientset)},
+		config: config.Remediation{
+			MaxNodes: 2,
+			Labels:   map[string]string{"perf": "failed"},
+			Taints:   []config.Taint{{Key: "perf", Value: "failed", Effect: "NoSchedule"}},
+			Cordon:   true,
+			Annotate: true,
+		},
+		now: func() time.Time {
+			return now
+		},
+	}
+
+	plan := r.Plan([]*reports.NodeEvaluation{
+		{NodeName: "node-a", Violations: []string{"Ping Test: average latency 2s exceeded 1s"}},
+		{NodeName: "node-b", Violations: []string{"Ping Test: provisioning failed: image pull error"}},
+		{NodeName: "node-c", Passed: true},
+		{NodeName: "node-d", Violations: []string{"Ping Test: failed requests 50.00% exceeded 5.00%"}},
+	})
+	if len(plan.Actions) != 8 {
+		t.Errorf("expected 4 actions for each of the 2 nodes, but got %d", len(plan.Actions))
+	}
+	if len(plan.SkippedNodes) != 1 || plan.SkippedNodes[0] != "node-d" {
+		t.Errorf("expected node-d to be skipped due to the cap, but got %v", plan.SkippedNodes)
+	}
+
+	output := &strings.Builder{}
+	err := WritePlan(plan, output)
+	if err != nil {
+		t.Fatalf("failed to write plan: %v", err)
+	}
+	if !strings.Contains(output.String(), "perf=failed:NoSchedule") || !strings.Contains(output.String(), "node-d") {
+		t.Errorf("expected the plan to include the taint and the skipped node, but got\n%s", output.String())
+	}
+
+	ctx := context.Background()
+	err = r.Apply(ctx, plan)
+	if err != nil {
+		t.Fatalf("failed to apply plan: %v", err)
+	}
+	for _, nodeName := range []string{"node-a", "node-b"} {
+		node, err := clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get node %s: %v", nodeName, err)
+		}
+		if node.GetLabels()["perf"] != "failed" {
+			t.Errorf("expected %s to be labelled, but got %v", nodeName, node.GetLabels())
+		}
+		if !node.Spec.Unschedulable {
+			t.Errorf("expected %s to be cordoned", nodeName)
+		}
+		if !strings.HasPrefix(node.GetAnnotations()[EvaluationAnnotation], "failed: Ping Test") ||
+			node.GetAnnotations()[EvaluatedAtAnnotation] != "2024-01-01T12:00:00Z" {
+			t.Errorf("expected %s to be annotated with the evaluation, but got %v", nodeName, node.GetAnnotations())
+		}
+		perfTaints := 0
+		for _, taint := range node.Spec.Taints {
+			if taint.Key == "perf" {
+				perfTaints++
+				if taint.Value != "failed" {
+					t.Errorf("expected the perf taint of %s to be replaced, but got %s", nodeName, taint.ToString())
+				}
+			}
+		}
+		if perfTaints != 1 {
+			t.Errorf("expected %s to have a single perf taint, but got %v", nodeName, node.Spec.Taints)
+		}
+	}
+	for _, nodeName := range []string{"node-c", "node-d"} {
+		node, err := clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get node %s: %v", nodeName, err)
+		}
+		if node.Spec.Unschedulable || len(node.GetLabels()) > 0 || len(node.Spec.Taints) > 0 {
+			t.Errorf("expected %s not to be remediated, but got %+v", nodeName, node)
+		}
+	}
+}
+
+func TestMultiClusterRemediator(t *testing.T) {
+	clientsets := map[string]*fake.Clientset{
+		"eu": fake.NewClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}}),
+		"us": fake.NewClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}}),
+	}
+	k8sClients := map[string]k8s.Interface{}
+	for cluster, clientset := range clientsets {
+		k8sClients[cluster] = k8s.NewFromClientset(clientset)
+	}
+	r := NewMultiClusterRemediator(k8sClients, config.Remediation{
+		MaxNodes: 1,
+		Cordon:   true,
+	}, zap.NewNop().Sugar())
+
+	// The cap on the number of nodes applies across all the clusters
+	plan := r.Plan([]*reports.NodeEvaluation{
+		{Cluster: "us", NodeName: "node-a", Violations: []string{"Ping Test: average latency 2s exceeded 1s"}},
+		{Cluster: "eu", NodeName: "node-a", Violations: []string{"Ping Test: average latency 2s exceeded 1s"}},
+	})
+	if len(plan.Actions) != 1 || plan.Actions[0].Cluster != "us" {
+		t.Fatalf("expected only the node of the first cluster to be actioned, but got %+v", plan.Actions)
+	}
+	if len(plan.SkippedNodes) != 1 || plan.SkippedNodes[0] != "eu/node-a" {
+		t.Errorf("expected eu/node-a to be skipped due to the cap, but got %v", plan.SkippedNodes)
+	}
+
+	ctx := context.Background()
+	err := r.Apply(ctx, plan)
+	if err != nil {
+		t.Fatalf("failed to apply plan: %v", err)
+	}
+	for cluster, cordoned := range map[string]bool{"us": true, "eu": false} {
+		node, err := clientsets[cluster].CoreV1().Nodes().Get(ctx, "node-a", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get node %s/node-a: %v", cluster, err)
+		}
+		if node.Spec.Unschedulable != cordoned {
+			t.Errorf("expected node %s/node-a to be cordoned %t, but got %t", cluster, cordoned, node.Spec.Unschedulable)
+		}
+	}
+
+	err = r.Apply(ctx, &Plan{Actions: []*Action{{Cluster: "apac", NodeName: "node-a", Type: ActionCordon}}})
+	if err == nil {
+		t.Errorf("expected the action on a node of an unknown cluster to fail")
+	}
+}
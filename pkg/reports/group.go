@@ -0,0 +1,138 @@
+package reports
+
+import (
+	"sort"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/evaluator"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Node fields which can be used as report columns or for grouping. Any other name is treated as a node label.
+const (
+	NodeFieldInstanceType     = "instanceType"
+	NodeFieldZone             = "zone"
+	NodeFieldNodePool         = "nodePool"
+	NodeFieldKernelVersion    = "kernelVersion"
+	NodeFieldOSImage          = "osImage"
+	NodeFieldContainerRuntime = "containerRuntime"
+	NodeFieldKubeletVersion   = "kubeletVersion"
+	NodeFieldCPU              = "cpu"
+	NodeFieldMemory           = "memory"
+	NodeFieldAge              = "age"
+)
+
+type GroupedTestSuiteResult struct {
+	Name         string
+	GroupBy      string
+	GroupResults []*GroupResult
+}
+
+// GroupedReport is the JSON report written when the results are grouped, with the grouped results next to
+// the results of the nodes.
+type GroupedReport struct {
+	Results []*TestSuiteResult
+	Groups  []*GroupedTestSuiteResult
+}
+
+type GroupResult struct {
+	Group                   string
+	Cluster                 string
+	NodeCount               int
+	RequestCount            int
+	AverageLatency          time.Duration
+	FailedRequestCount      int
+	FailedPercentage        float64
+	ProvisioningFailedCount int
+}
+
+// NodeFieldValue returns the value of a node field (or the node label if the field is not known).
+func NodeFieldValue(node *evaluator.Node, field string) string {
+	if node == nil {
+		return ""
+	}
+	switch field {
+	case NodeFieldInstanceType:
+		return node.InstanceType
+	case NodeFieldZone:
+		return node.Zone
+	case NodeFieldNodePool:
+		return node.NodePool
+	case NodeFieldKernelVersion:
+		return node.KernelVersion
+	case NodeFieldOSImage:
+		return node.OSImage
+	case NodeFieldContainerRuntime:
+		return node.ContainerRuntimeVersion
+	case NodeFieldKubeletVersion:
+		return node.KubeletVersion
+	case NodeFieldCPU:
+		if cpu, ok := node.Allocatable[corev1.ResourceCPU]; ok {
+			return cpu.String()
+		}
+		return ""
+	case NodeFieldMemory:
+		if memory, ok := node.Allocatable[corev1.ResourceMemory]; ok {
+			return memory.String()
+		}
+		return ""
+	case NodeFieldAge:
+		if node.CreationTimestamp.IsZero() {
+			return ""
+		}
+		return time.Since(node.CreationTimestamp).Round(time.Minute).String()
+	default:
+		return node.Labels[field]
+	}
+}
+
+// GroupTestSuiteResults aggregates the results of the nodes sharing the same value of the node field or label.
+// The results of different clusters are aggregated separately.
+func GroupTestSuiteResults(testSuiteResults []*TestSuiteResult, groupBy string) []*GroupedTestSuiteResult {
+	groupedTestSuiteResults := []*GroupedTestSuiteResult{}
+	for _, testSuiteResult := range testSuiteResults {
+		groups := map[string]*GroupResult{}
+		totalLatencies := map[string]time.Duration{}
+		for _, testResult := range testSuiteResult.TestResults {
+			groupName := NodeFieldValue(testResult.Node, groupBy)
+			groupKey := testResult.Cluster + "/" + groupName
+			group, ok := groups[groupKey]
+			if !ok {
+				group = &GroupResult{
+					Group:   groupName,
+					Cluster: testResult.Cluster,
+				}
+				groups[groupKey] = group
+			}
+			group.NodeCount++
+			if testResult.ProvisioningFailed {
+				group.ProvisioningFailedCount++
+				continue
+			}
+			group.RequestCount += testResult.RequestCount
+			group.FailedRequestCount += testResult.FailedRequestCount
+			totalLatencies[groupKey] += testResult.AverageLatency * time.Duration(testResult.RequestCount)
+		}
+
+		groupResults := []*GroupResult{}
+		for groupKey, group := range groups {
+			if group.RequestCount > 0 {
+				group.AverageLatency = totalLatencies[groupKey] / time.Duration(group.RequestCount)
+				group.FailedPercentage = float64(group.FailedRequestCount) / float64(group.RequestCount) * 100
+			}
+			groupResults = append(groupResults, group)
+		}
+		sort.Slice(groupResults, func(i, j int) bool {
+			if groupResults[i].Group != groupResults[j].Group {
+				return groupResults[i].Group < groupResults[j].Group
+			}
+			return groupResults[i].Cluster < groupResults[j].Cluster
+		})
+		groupedTestSuiteResults = append(groupedTestSuiteResults, &GroupedTestSuiteResult{
+			Name:         testSuiteResult.Name,
+			GroupBy:      groupBy,
+			GroupResults: groupResults,
+		})
+	}
+	return groupedTestSuiteResults
+}
@@ -0,0 +1,56 @@
+package reports
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/evaluator"
+)
+
+func TestGroupTestSuiteResults(t *testing.T) {
+	zoneA := &evaluator.Node{Name: "node-a", Zone: "zone-a", Labels: map[string]string{"pool": "general"}}
+	zoneB := &evaluator.Node{Name: "node-b", Zone: "zone-b", Labels: map[string]string{"pool": "general"}}
+	zoneBFailed := &evaluator.Node{Name: "node-c", Zone: "zone-b", Labels: map[string]string{"pool": "gpu"}}
+	testSuiteResults := []*TestSuiteResult{
+		{
+			Name: "Ping Test",
+			TestResults: []*TestResult{
+				{NodeName: "node-a", Node: zoneA, RequestCount: 10, AverageLatency: 10 * time.Millisecond, FailedRequestCount: 1},
+				{NodeName: "node-b", Node: zoneB, RequestCount: 30, AverageLatency: 30 * time.Millisecond, FailedRequestCount: 3},
+				{NodeName: "node-c", Node: zoneBFailed, ProvisioningFailed: true},
+			},
+		},
+	}
+
+	grouped := GroupTestSuiteResults(testSuiteResults, NodeFieldZone)
+	if len(grouped) != 1 || len(grouped[0].GroupResults) != 2 {
+		t.Fatalf("expected 1 test suite with 2 groups, but got %+v", grouped)
+	}
+	zoneAResult := grouped[0].GroupResults[0]
+	if zoneAResult.Group != "zone-a" || zoneAResult.NodeCount != 1 || zoneAResult.AverageLatency != 10*time.Millisecond {
+		t.Errorf("unexpected result for zone-a: %+v", zoneAResult)
+	}
+	zoneBResult := grouped[0].GroupResults[1]
+	if zoneBResult.Group != "zone-b" || zoneBResult.NodeCount != 2 || zoneBResult.ProvisioningFailedCount != 1 ||
+		zoneBResult.AverageLatency != 30*time.Millisecond || zoneBResult.FailedPercentage != 10 {
+		t.Errorf("unexpected result for zone-b: %+v", zoneBResult)
+	}
+
+	grouped = GroupTestSuiteResults(testSuiteResults, "pool")
+	generalResult := grouped[0].GroupResults[0]
+	if generalResult.Group != "general" || generalResult.RequestCount != 40 || generalResult.AverageLatency != 25*time.Millisecond {
+		t.Errorf("unexpected result for the general pool: %+v", generalResult)
+	}
+
+	// The same groups of different clusters are compared side by side
+	testSuiteResults[0].TestResults[1].Cluster = "west"
+	grouped = GroupTestSuiteResults(testSuiteResults, "pool")
+	if len(grouped[0].GroupResults) != 3 {
+		t.Fatalf("expected the general pool to be split by cluster, but got %+v", grouped[0].GroupResults)
+	}
+	eastResult, westResult := grouped[0].GroupResults[0], grouped[0].GroupResults[1]
+	if eastResult.Group != "general" || eastResult.Cluster != "" || eastResult.AverageLatency != 10*time.Millisecond ||
+		westResult.Group != "general" || westResult.Cluster != "west" || westResult.AverageLatency != 30*time.Millisecond {
+		t.Errorf("unexpected results for the general pool of the clusters: %+v and %+v", eastResult, westResult)
+	}
+}
@@ -12,10 +12,18 @@ type TestSuiteResult struct {
 }
 
 type TestResult struct {
+	Cluster            string
 	NodeName           string
+	Node               *evaluator.Node
+	RequestCount       int
 	AverageLatency     time.Duration
 	FailedRequestCount int
 	FailedPercentage   float64
+	ResourceUsage      *evaluator.ResourceUsage
+	NodeUtilisation    *evaluator.NodeUtilisation
+
+	ProvisioningFailed        bool
+	ProvisioningFailureReason string
 }
 
 func CalculateTestSuiteResults(testSuites []*evaluator.TestSuite) []*TestSuiteResult {
@@ -38,18 +46,62 @@ func calculateTestSuiteResult(testSuite *evaluator.TestSuite) *TestSuiteResult {
 }
 
 func calculateTestResult(test *evaluator.Test) *TestResult {
+	if test.ProvisioningFailed {
+		return &TestResult{
+			Cluster:                   test.Cluster,
+			NodeName:                  test.NodeName,
+			Node:                      test.Node,
+			ProvisioningFailed:        true,
+			ProvisioningFailureReason: test.ProvisioningFailureReason,
+		}
+	}
 	if test.TotalRequestsCount == 0 {
 		return &TestResult{
+			Cluster:            test.Cluster,
 			NodeName:           test.NodeName,
+			Node:               test.Node,
 			AverageLatency:     0,
 			FailedRequestCount: test.TotalFailedRequestsCount,
 			FailedPercentage:   0,
+			ResourceUsage:      test.ResourceUsage,
+			NodeUtilisation:    test.NodeUtilisation,
 		}
 	}
 	return &TestResult{
+		Cluster:            test.Cluster,
 		NodeName:           test.NodeName,
+		Node:               test.Node,
+		RequestCount:       test.TotalRequestsCount,
 		AverageLatency:     time.Duration(test.TotalLatency.Nanoseconds() / int64(test.TotalRequestsCount)),
 		FailedRequestCount: test.TotalFailedRequestsCount,
 		FailedPercentage:   float64(test.TotalFailedRequestsCount) / float64(test.TotalRequestsCount) * 100,
+		ResourceUsage:      test.ResourceUsage,
+		NodeUtilisation:    test.NodeUtilisation,
+	}
+}
+
+// QualifiedNodeName is the name of the node prefixed by the cluster, since node names are only unique within a cluster.
+func QualifiedNodeName(cluster string, nodeName string) string {
+	if cluster == "" {
+		return nodeName
+	}
+	return cluster + "/" + nodeName
+}
+
+// FilterByCluster returns the results of the nodes in the cluster, keeping the test suites.
+func FilterByCluster(testSuiteResults []*TestSuiteResult, cluster string) []*TestSuiteResult {
+	clusterResults := []*TestSuiteResult{}
+	for _, testSuiteResult := range testSuiteResults {
+		testResults := []*TestResult{}
+		for _, testResult := range testSuiteResult.TestResults {
+			if testResult.Cluster == cluster {
+				testResults = append(testResults, testResult)
+			}
+		}
+		clusterResults = append(clusterResults, &TestSuiteResult{
+			Name:        testSuiteResult.Name,
+			TestResults: testResults,
+		})
 	}
+	return clusterResults
 }
@@ -0,0 +1,52 @@
+package reports
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/evaluator"
+)
+
+func TestCalculateTestSuiteResults(t *testing.T) {
+	testSuiteResults := CalculateTestSuiteResults([]*evaluator.TestSuite{
+		{
+			Name: "Ping Test",
+			Tests: []*evaluator.Test{
+				{
+					NodeName:                 "node-a",
+					TotalRequestsCount:       10,
+					TotalFailedRequestsCount: 2,
+					TotalLatency:             time.Second,
+				},
+				{
+					NodeName: "node-b",
+				},
+				{
+					NodeName:                  "node-c",
+					ProvisioningFailed:        true,
+					ProvisioningFailureReason: "image pull error",
+				},
+			},
+		},
+	})
+	if len(testSuiteResults) != 1 || len(testSuiteResults[0].TestResults) != 3 {
+		t.Fatalf("expected 1 test suite with 3 results, but got %+v", testSuiteResults)
+	}
+	results := testSuiteResults[0].TestResults
+
+	if results[0].AverageLatency != 100*time.Millisecond {
+		t.Errorf("expected average latency of 100ms, but got %s", results[0].AverageLatency)
+	}
+	if results[0].FailedRequestCount != 2 || results[0].FailedPercentage != 20 {
+		t.Errorf("expected 2 (20%%) failed requests, but got %d (%.2f%%)", results[0].FailedRequestCount,
+			results[0].FailedPercentage)
+	}
+
+	if results[1].AverageLatency != 0 || results[1].FailedPercentage != 0 {
+		t.Errorf("expected empty results for a node without requests, but got %+v", results[1])
+	}
+
+	if !results[2].ProvisioningFailed || results[2].ProvisioningFailureReason != "image pull error" {
+		t.Errorf("expected provisioning failure to be reported, but got %+v", results[2])
+	}
+}
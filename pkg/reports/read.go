@@ -0,0 +1,40 @@
+package reports
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ReadResults reads the JSON reports in the input. The reports are appended to the report file by each
+// run, and hence the input may contain multiple reports which are returned in the order they were written.
+// Both the plain reports and the grouped reports are read.
+func ReadResults(input io.Reader) ([][]*TestSuiteResult, error) {
+	runs := [][]*TestSuiteResult{}
+	decoder := json.NewDecoder(input)
+	for {
+		report := json.RawMessage{}
+		err := decoder.Decode(&report)
+		if errors.Is(err, io.EOF) {
+			return runs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse report %d: %w", len(runs)+1, err)
+		}
+
+		testSuiteResults := []*TestSuiteResult{}
+		if bytes.HasPrefix(report, []byte("{")) {
+			groupedReport := &GroupedReport{}
+			err = json.Unmarshal(report, groupedReport)
+			testSuiteResults = groupedReport.Results
+		} else {
+			err = json.Unmarshal(report, &testSuiteResults)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse report %d: %w", len(runs)+1, err)
+		}
+		runs = append(runs, testSuiteResults)
+	}
+}
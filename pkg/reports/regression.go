@@ -0,0 +1,185 @@
+package reports
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+)
+
+type Metric string
+
+const (
+	MetricAverageLatency     Metric = "averageLatency"
+	MetricFailedPercentage   Metric = "failedPercentage"
+	MetricProvisioningFailed Metric = "provisioningFailed"
+)
+
+// Format formats a value of the metric for the reports.
+func (m Metric) Format(value float64) string {
+	switch m {
+	case MetricAverageLatency:
+		return time.Duration(value).Round(time.Microsecond).String()
+	case MetricFailedPercentage:
+		return fmt.Sprintf("%.2f%%", value)
+	default:
+		return fmt.Sprintf("%g", value)
+	}
+}
+
+// Regression is a metric of a node in a test suite which got worse in the latest run compared to the
+// baseline of the previous runs of the same node.
+type Regression struct {
+	Cluster  string
+	NodeName string
+	Suite    string
+	Metric   Metric
+	// Baseline is the mean of the metric across the baseline runs
+	Baseline     float64
+	Latest       float64
+	BaselineRuns int
+	// Deviation is the number of standard deviations of the baseline by which the latest value exceeded the
+	// baseline, or zero if the baseline had no variance
+	Deviation float64
+}
+
+// DetectRegressions compares the results of each node in the latest run against the rolling baseline of its
+// own results in the preceding runs. The runs are ordered from the oldest to the newest. A metric regressed
+// when it exceeded the baseline by more than the allowed number of standard deviations as well as by the
+// minimum change, so that the noise of stable nodes is not reported.
+func DetectRegressions(runs [][]*TestSuiteResult, regressionConfig config.Regression) []*Regression {
+	regressions := []*Regression{}
+	if len(runs) < 2 {
+		return regressions
+	}
+	latestRun := runs[len(runs)-1]
+	baselineRuns := runs[max(0, len(runs)-1-regressionConfig.BaselineRuns) : len(runs)-1]
+	for _, testSuiteResult := range latestRun {
+		for _, latest := range testSuiteResult.TestResults {
+			baseline := []*TestResult{}
+			for _, run := range baselineRuns {
+				if testResult := findTestResult(run, testSuiteResult.Name, latest); testResult != nil {
+					baseline = append(baseline, testResult)
+				}
+			}
+			if len(baseline) < regressionConfig.MinBaselineRuns {
+				continue
+			}
+			regressions = append(regressions, compareToBaseline(testSuiteResult.Name, latest, baseline,
+				regressionConfig)...)
+		}
+	}
+	return regressions
+}
+
+func compareToBaseline(suite string, latest *TestResult, baseline []*TestResult, regressionConfig config.Regression) []*Regression {
+	regressions := []*Regression{}
+	provisioningFailures := 0
+	latencies := []float64{}
+	failedPercentages := []float64{}
+	for _, testResult := range baseline {
+		if testResult.ProvisioningFailed {
+			provisioningFailures++
+			continue
+		}
+		if testResult.RequestCount == 0 {
+			continue
+		}
+		latencies = append(latencies, float64(testResult.AverageLatency.Nanoseconds()))
+		failedPercentages = append(failedPercentages, testResult.FailedPercentage)
+	}
+	if latest.ProvisioningFailed {
+		// Nodes which repeatedly fail to provision are reported by the thresholds instead
+		if provisioningFailures == 0 {
+			regressions = append(regressions, &Regression{
+				Cluster:      latest.Cluster,
+				NodeName:     latest.NodeName,
+				Suite:        suite,
+				Metric:       MetricProvisioningFailed,
+				Baseline:     0,
+				Latest:       1,
+				BaselineRuns: len(baseline),
+			})
+		}
+		return regressions
+	}
+	if latest.RequestCount == 0 || len(latencies) < regressionConfig.MinBaselineRuns {
+		return regressions
+	}
+
+	latestLatency := float64(latest.AverageLatency.Nanoseconds())
+	mean, deviation := deviationFromBaseline(latencies, latestLatency)
+	if deviation > regressionConfig.MaxDeviation &&
+		(latestLatency-mean)/mean*100 >= regressionConfig.MinLatencyIncreasePercentage {
+		regressions = append(regressions, &Regression{
+			Cluster:      latest.Cluster,
+			NodeName:     latest.NodeName,
+			Suite:        suite,
+			Metric:       MetricAverageLatency,
+			Baseline:     mean,
+			Latest:       latestLatency,
+			BaselineRuns: len(latencies),
+			Deviation:    finiteOrZero(deviation),
+		})
+	}
+	mean, deviation = deviationFromBaseline(failedPercentages, latest.FailedPercentage)
+	if deviation > regressionConfig.MaxDeviation &&
+		latest.FailedPercentage-mean >= regressionConfig.MinFailedPercentageIncrease {
+		regressions = append(regressions, &Regression{
+			Cluster:      latest.Cluster,
+			NodeName:     latest.NodeName,
+			Suite:        suite,
+			Metric:       MetricFailedPercentage,
+			Baseline:     mean,
+			Latest:       latest.FailedPercentage,
+			BaselineRuns: len(failedPercentages),
+			Deviation:    finiteOrZero(deviation),
+		})
+	}
+	return regressions
+}
+
+// deviationFromBaseline returns the mean of the baseline and the number of standard deviations by which the
+// value exceeds it. Any increase over a baseline without variance is an infinite deviation.
+func deviationFromBaseline(baseline []float64, value float64) (float64, float64) {
+	sum := 0.0
+	for _, v := range baseline {
+		sum += v
+	}
+	mean := sum / float64(len(baseline))
+	if value <= mean {
+		return mean, 0
+	}
+	squaredDifferences := 0.0
+	for _, v := range baseline {
+		squaredDifferences += (v - mean) * (v - mean)
+	}
+	if len(baseline) < 2 || squaredDifferences == 0 {
+		return mean, math.Inf(1)
+	}
+	standardDeviation := math.Sqrt(squaredDifferences / float64(len(baseline)-1))
+	return mean, (value - mean) / standardDeviation
+}
+
+func finiteOrZero(value float64) float64 {
+	if math.IsInf(value, 0) {
+		return 0
+	}
+	return value
+}
+
+// findTestResult finds the result of the same node as the provided result in the test suite of the run.
+func findTestResult(run []*TestSuiteResult, suite string, testResult *TestResult) *TestResult {
+	for _, testSuiteResult := range run {
+		if testSuiteResult.Name != suite {
+			continue
+		}
+		for _, runTestResult := range testSuiteResult.TestResults {
+			if runTestResult.Cluster == testResult.Cluster && runTestResult.NodeName == testResult.NodeName {
+				return runTestResult
+			}
+		}
+	}
+	return nil
+}
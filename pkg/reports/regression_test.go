@@ -0,0 +1,96 @@
+package reports
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+)
+
+func TestDetectRegressions(t *testing.T) {
+	newRun := func(slowLatency time.Duration, flakyFailedPercentage float64, brokenProvisioningFailed bool) []*TestSuiteResult {
+		return []*TestSuiteResult{
+			{
+				Name: "Ping Test",
+				TestResults: []*TestResult{
+					// node-slow is consistently slower than the other nodes, and hence only its own baseline flags it
+					{NodeName: "node-slow", RequestCount: 10, AverageLatency: slowLatency},
+					{NodeName: "node-stable", RequestCount: 10, AverageLatency: 10 * time.Millisecond},
+					{NodeName: "node-flaky", RequestCount: 10, AverageLatency: 10 * time.Millisecond,
+						FailedPercentage: flakyFailedPercentage},
+					{NodeName: "node-broken", RequestCount: 10, AverageLatency: 10 * time.Millisecond,
+						ProvisioningFailed: brokenProvisioningFailed},
+				},
+			},
+		}
+	}
+	runs := [][]*TestSuiteResult{
+		newRun(100*time.Millisecond, 0, false),
+		newRun(104*time.Millisecond, 0.5, false),
+		newRun(98*time.Millisecond, 0, false),
+		newRun(101*time.Millisecond, 0, false),
+		newRun(150*time.Millisecond, 10, true),
+	}
+	regressionConfig := config.Regression{
+		BaselineRuns:                 10,
+		MinBaselineRuns:              3,
+		MaxDeviation:                 3,
+		MinLatencyIncreasePercentage: 10,
+		MinFailedPercentageIncrease:  1,
+	}
+
+	regressions := DetectRegressions(runs, regressionConfig)
+	found := []string{}
+	for _, regression := range regressions {
+		found = append(found, regression.NodeName+"/"+string(regression.Metric))
+	}
+	expected := "node-slow/averageLatency,node-flaky/failedPercentage,node-broken/provisioningFailed"
+	if strings.Join(found, ",") != expected {
+		t.Fatalf("expected regressions %s, but got %s", expected, strings.Join(found, ","))
+	}
+	if regressions[0].BaselineRuns != 4 || regressions[0].Baseline != float64(100750*time.Microsecond) ||
+		regressions[0].Deviation < 3 {
+		t.Errorf("expected node-slow to be compared against the 4 previous runs, but got %+v", regressions[0])
+	}
+
+	// The same increase within a noisy baseline is not a regression
+	runs[1][0].TestResults[0].AverageLatency = 180 * time.Millisecond
+	for _, regression := range DetectRegressions(runs, regressionConfig) {
+		if regression.NodeName == "node-slow" {
+			t.Errorf("expected no regression of node-slow with a noisy baseline, but got %+v", regression)
+		}
+	}
+
+	regressionConfig.MinBaselineRuns = 5
+	if regressions = DetectRegressions(runs, regressionConfig); len(regressions) != 0 {
+		t.Errorf("expected no regressions without enough baseline runs, but got %d", len(regressions))
+	}
+}
+
+func TestReadResults(t *testing.T) {
+	// Each run appends a report to the report file
+	runs, err := ReadResults(strings.NewReader(`[{"Name": "Ping Test", "TestResults": [{"NodeName": "node-a"}]}]
+[{"Name": "Ping Test", "TestResults": [{"NodeName": "node-a"}]}, {"Name": "CPU Intensive Load Test"}]`))
+	if err != nil {
+		t.Fatalf("failed to read results: %v", err)
+	}
+	if len(runs) != 2 || len(runs[0]) != 1 || len(runs[1]) != 2 {
+		t.Errorf("expected 2 runs, but got %v", runs)
+	}
+
+	// Grouped reports contain the results next to the groups
+	runs, err = ReadResults(strings.NewReader(`[{"Name": "Ping Test", "TestResults": [{"NodeName": "node-a"}]}]
+{"Results": [{"Name": "Ping Test", "TestResults": [{"NodeName": "node-a"}]}], "Groups": [{"Name": "Ping Test"}]}`))
+	if err != nil {
+		t.Fatalf("failed to read grouped results: %v", err)
+	}
+	if len(runs) != 2 || len(runs[1]) != 1 || runs[1][0].TestResults[0].NodeName != "node-a" {
+		t.Errorf("expected 2 runs including the grouped report, but got %v", runs)
+	}
+
+	_, err = ReadResults(strings.NewReader(`[{"Name": `))
+	if err == nil {
+		t.Errorf("expected an error for a truncated report")
+	}
+}
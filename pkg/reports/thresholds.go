@@ -0,0 +1,69 @@
+package reports
+
+import (
+	"fmt"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/evaluator"
+)
+
+// NodeEvaluation is the outcome of checking the results of a node against the thresholds.
+type NodeEvaluation struct {
+	Cluster    string
+	NodeName   string
+	Node       *evaluator.Node
+	Passed     bool
+	Violations []string
+}
+
+// EvaluateThresholds checks the results of each node across all the test suites against the thresholds.
+// The nodes are returned in the order they first appear in the results.
+func EvaluateThresholds(testSuiteResults []*TestSuiteResult, thresholds config.Thresholds) []*NodeEvaluation {
+	evaluations := []*NodeEvaluation{}
+	evaluationsByNode := map[string]*NodeEvaluation{}
+	for _, testSuiteResult := range testSuiteResults {
+		for _, testResult := range testSuiteResult.TestResults {
+			evaluation, ok := evaluationsByNode[QualifiedNodeName(testResult.Cluster, testResult.NodeName)]
+			if !ok {
+				evaluation = &NodeEvaluation{
+					Cluster:    testResult.Cluster,
+					NodeName:   testResult.NodeName,
+					Node:       testResult.Node,
+					Violations: []string{},
+				}
+				evaluationsByNode[QualifiedNodeName(testResult.Cluster, testResult.NodeName)] = evaluation
+				evaluations = append(evaluations, evaluation)
+			}
+			evaluation.Violations = append(evaluation.Violations,
+				findViolations(testSuiteResult.Name, testResult, thresholds)...)
+		}
+	}
+	for _, evaluation := range evaluations {
+		evaluation.Passed = len(evaluation.Violations) == 0
+	}
+	return evaluations
+}
+
+func findViolations(suite string, testResult *TestResult, thresholds config.Thresholds) []string {
+	if testResult.ProvisioningFailed {
+		if thresholds.FailOnProvisioningFailure {
+			return []string{fmt.Sprintf("%s: provisioning failed: %s", suite, testResult.ProvisioningFailureReason)}
+		}
+		return nil
+	}
+	violations := []string{}
+	for _, threshold := range thresholds.Suites {
+		if threshold.Suite != "" && threshold.Suite != suite {
+			continue
+		}
+		if threshold.MaxAverageLatency > 0 && testResult.AverageLatency > threshold.MaxAverageLatency {
+			violations = append(violations, fmt.Sprintf("%s: average latency %s exceeded %s", suite,
+				testResult.AverageLatency, threshold.MaxAverageLatency))
+		}
+		if threshold.MaxFailedPercentage != nil && testResult.FailedPercentage > *threshold.MaxFailedPercentage {
+			violations = append(violations, fmt.Sprintf("%s: failed requests %.2f%% exceeded %.2f%%", suite,
+				testResult.FailedPercentage, *threshold.MaxFailedPercentage))
+		}
+	}
+	return violations
+}
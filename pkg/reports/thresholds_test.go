@@ -0,0 +1,54 @@
+package reports
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+)
+
+func TestEvaluateThresholds(t *testing.T) {
+	maxFailedPercentage := 5.0
+	evaluations := EvaluateThresholds([]*TestSuiteResult{
+		{
+			Name: "Ping Test",
+			TestResults: []*TestResult{
+				{NodeName: "node-a", AverageLatency: 10 * time.Millisecond},
+				{NodeName: "node-b", AverageLatency: 200 * time.Millisecond},
+				{NodeName: "node-c", ProvisioningFailed: true, ProvisioningFailureReason: "image pull error"},
+			},
+		},
+		{
+			Name: "CPU Intensive Load Test",
+			TestResults: []*TestResult{
+				{NodeName: "node-a", AverageLatency: 300 * time.Millisecond, FailedPercentage: 10},
+				{NodeName: "node-b", AverageLatency: 400 * time.Millisecond},
+				{NodeName: "node-c", ProvisioningFailed: true, ProvisioningFailureReason: "image pull error"},
+			},
+		},
+	}, config.Thresholds{
+		FailOnProvisioningFailure: true,
+		Suites: []config.SuiteThreshold{
+			{Suite: "Ping Test", MaxAverageLatency: 100 * time.Millisecond},
+			{MaxFailedPercentage: &maxFailedPercentage},
+		},
+	})
+
+	if len(evaluations) != 3 {
+		t.Fatalf("expected evaluations of 3 nodes, but got %d", len(evaluations))
+	}
+	expectedViolations := map[string]int{
+		"node-a": 1,
+		"node-b": 1,
+		"node-c": 2,
+	}
+	for _, evaluation := range evaluations {
+		if len(evaluation.Violations) != expectedViolations[evaluation.NodeName] {
+			t.Errorf("expected %d violations for %s, but got %v", expectedViolations[evaluation.NodeName],
+				evaluation.NodeName, evaluation.Violations)
+		}
+		if evaluation.Passed {
+			t.Errorf("expected %s to fail the thresholds", evaluation.NodeName)
+		}
+	}
+}
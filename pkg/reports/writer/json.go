@@ -8,12 +8,23 @@ import (
 	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/reports"
 )
 
-type jsonWriter struct{}
+type jsonWriter struct {
+	options Options
+}
 
 var _ Writer = &jsonWriter{}
 
+// Write writes the results as an array, or as an object containing the results and the grouped results if
+// the results are grouped.
 func (w *jsonWriter) Write(results []*reports.TestSuiteResult, output io.Writer) error {
-	b, err := json.MarshalIndent(results, "", "\t")
+	var report interface{} = results
+	if w.options.GroupBy != "" {
+		report = &reports.GroupedReport{
+			Results: results,
+			Groups:  reports.GroupTestSuiteResults(results, w.options.GroupBy),
+		}
+	}
+	b, err := json.MarshalIndent(report, "", "\t")
 	if err != nil {
 		return fmt.Errorf("failed to convert test results to json: %+w", err)
 	}
@@ -23,3 +34,15 @@ func (w *jsonWriter) Write(results []*reports.TestSuiteResult, output io.Writer)
 	}
 	return err
 }
+
+func (w *jsonWriter) WriteRegressions(regressions []*reports.Regression, output io.Writer) error {
+	b, err := json.MarshalIndent(regressions, "", "\t")
+	if err != nil {
+		return fmt.Errorf("failed to convert regressions to json: %+w", err)
+	}
+	_, err = output.Write(b)
+	if err != nil {
+		return fmt.Errorf("failed to write regressions to output: %+w", err)
+	}
+	return err
+}
@@ -6,10 +6,13 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/evaluator"
 	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/reports"
 )
 
-type textWriter struct{}
+type textWriter struct {
+	options Options
+}
 
 var _ Writer = &textWriter{}
 
@@ -20,27 +23,328 @@ func (w *textWriter) Write(testSuiteResults []*reports.TestSuiteResult, output i
 			return fmt.Errorf("failed to print title of console report %s: %w", testSuiteResult.Name, err)
 		}
 
-		w := tabwriter.NewWriter(output, 1, 1, 3, ' ', 0)
-		_, err = fmt.Fprintln(w, "NODE\tAVERAGE LATENCY\tFAILED REQUESTS\t")
+		showResourceUsage := hasResourceUsage(testSuiteResult)
+		resourceUsageHeaders := ""
+		if showResourceUsage {
+			resourceUsageHeaders = "CPU\tCPU THROTTLED\tPEAK MEMORY\tNETWORK RX / TX\t" + nodeUtilisationHeaders
+		}
+
+		tw := tabwriter.NewWriter(output, 1, 1, 3, ' ', 0)
+		_, err = fmt.Fprintf(tw, "NODE\t%sAVERAGE LATENCY\tFAILED REQUESTS\t%s\n", w.columnHeaders(), resourceUsageHeaders)
 		if err != nil {
 			return fmt.Errorf("failed to write header of console report %s: %w", testSuiteResult.Name, err)
 		}
 		for _, testResult := range testSuiteResult.TestResults {
-			_, err = fmt.Fprintf(w, "%s\t%s\t%.2f%% (%d)\t\n", testResult.NodeName, testResult.AverageLatency, testResult.FailedPercentage, testResult.FailedRequestCount)
+			columns := ""
+			for _, column := range w.options.Columns {
+				columns += valueOrDash(reports.NodeFieldValue(testResult.Node, column)) + "\t"
+			}
+			if testResult.ProvisioningFailed {
+				_, err = fmt.Fprintf(tw, "%s\t%s-\tPROVISIONING FAILED: %s\t\n",
+					reports.QualifiedNodeName(testResult.Cluster, testResult.NodeName), columns, testResult.ProvisioningFailureReason)
+				if err != nil {
+					return fmt.Errorf("failed to write row of console report %s: %w", testSuiteResult.Name, err)
+				}
+				continue
+			}
+			resourceUsage := ""
+			if showResourceUsage {
+				resourceUsage = formatResourceUsage(testResult.ResourceUsage) + formatNodeUtilisation(testResult.NodeUtilisation)
+			}
+			_, err = fmt.Fprintf(tw, "%s\t%s%s\t%.2f%% (%d)\t%s\n", reports.QualifiedNodeName(testResult.Cluster, testResult.NodeName),
+				columns, testResult.AverageLatency, testResult.FailedPercentage, testResult.FailedRequestCount, resourceUsage)
 			if err != nil {
 				return fmt.Errorf("failed to write row of console report %s: %w", testSuiteResult.Name, err)
 			}
 		}
-		err = w.Flush()
+		err = tw.Flush()
 		if err != nil {
 			return fmt.Errorf("failed to flush console report %s: %w", testSuiteResult.Name, err)
 		}
 	}
+
+	err := w.writeNodeUtilisationBeforeTests(testSuiteResults, output)
+	if err != nil {
+		return err
+	}
+
+	err = w.writeNodeHealth(testSuiteResults, output)
+	if err != nil {
+		return err
+	}
+
+	if w.options.GroupBy != "" {
+		for _, groupedResult := range reports.GroupTestSuiteResults(testSuiteResults, w.options.GroupBy) {
+			err := w.writeGroupedResult(groupedResult, output)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (w *textWriter) WriteRegressions(regressions []*reports.Regression, output io.Writer) error {
+	err := w.writeTitle("Regressions", output)
+	if err != nil {
+		return fmt.Errorf("failed to print title of regressions report: %w", err)
+	}
+	if len(regressions) == 0 {
+		_, err = fmt.Fprintln(output, "No regressions found")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(output, 1, 1, 3, ' ', 0)
+	_, err = fmt.Fprintln(tw, "NODE\tSUITE\tMETRIC\tBASELINE\tLATEST\tDEVIATION\tBASELINE RUNS\t")
+	if err != nil {
+		return fmt.Errorf("failed to write header of regressions report: %w", err)
+	}
+	for _, regression := range regressions {
+		deviation := "-"
+		if regression.Deviation > 0 {
+			deviation = fmt.Sprintf("%.1fσ", regression.Deviation)
+		}
+		_, err = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t\n", reports.QualifiedNodeName(regression.Cluster, regression.NodeName), regression.Suite,
+			regression.Metric, regression.Metric.Format(regression.Baseline), regression.Metric.Format(regression.Latest),
+			deviation, regression.BaselineRuns)
+		if err != nil {
+			return fmt.Errorf("failed to write row of regressions report: %w", err)
+		}
+	}
+	err = tw.Flush()
+	if err != nil {
+		return fmt.Errorf("failed to flush regressions report: %w", err)
+	}
+	return nil
+}
+
+func (w *textWriter) writeGroupedResult(groupedResult *reports.GroupedTestSuiteResult, output io.Writer) error {
+	title := fmt.Sprintf("%s by %s", groupedResult.Name, groupedResult.GroupBy)
+	err := w.writeTitle(title, output)
+	if err != nil {
+		return fmt.Errorf("failed to print title of console report %s: %w", title, err)
+	}
+
+	showClusters := false
+	for _, group := range groupedResult.GroupResults {
+		showClusters = showClusters || group.Cluster != ""
+	}
+	clusterHeader := ""
+	if showClusters {
+		clusterHeader = "CLUSTER\t"
+	}
+
+	tw := tabwriter.NewWriter(output, 1, 1, 3, ' ', 0)
+	_, err = fmt.Fprintf(tw, "%s\t%sNODES\tAVERAGE LATENCY\tFAILED REQUESTS\tPROVISIONING FAILURES\t\n",
+		columnHeader(groupedResult.GroupBy), clusterHeader)
+	if err != nil {
+		return fmt.Errorf("failed to write header of console report %s: %w", title, err)
+	}
+	for _, group := range groupedResult.GroupResults {
+		cluster := ""
+		if showClusters {
+			cluster = valueOrDash(group.Cluster) + "\t"
+		}
+		_, err = fmt.Fprintf(tw, "%s\t%s%d\t%s\t%.2f%% (%d)\t%d\t\n", valueOrDash(group.Group), cluster, group.NodeCount, group.AverageLatency,
+			group.FailedPercentage, group.FailedRequestCount, group.ProvisioningFailedCount)
+		if err != nil {
+			return fmt.Errorf("failed to write row of console report %s: %w", title, err)
+		}
+	}
+	err = tw.Flush()
+	if err != nil {
+		return fmt.Errorf("failed to flush console report %s: %w", title, err)
+	}
+	return nil
+}
+
+// writeNodeUtilisationBeforeTests writes the utilisation of the nodes before the test services were provisioned.
+func (w *textWriter) writeNodeUtilisationBeforeTests(testSuiteResults []*reports.TestSuiteResult, output io.Writer) error {
+	nodes := []*reports.TestResult{}
+	visitedNodes := map[string]struct{}{}
+	for _, testSuiteResult := range testSuiteResults {
+		for _, testResult := range testSuiteResult.TestResults {
+			if testResult.Node == nil || testResult.Node.UtilisationBeforeTest == nil {
+				continue
+			}
+			if _, ok := visitedNodes[reports.QualifiedNodeName(testResult.Cluster, testResult.NodeName)]; ok {
+				continue
+			}
+			visitedNodes[reports.QualifiedNodeName(testResult.Cluster, testResult.NodeName)] = struct{}{}
+			nodes = append(nodes, testResult)
+		}
+	}
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	title := "Node Utilisation Before Tests"
+	err := w.writeTitle(title, output)
+	if err != nil {
+		return fmt.Errorf("failed to print title of console report %s: %w", title, err)
+	}
+	tw := tabwriter.NewWriter(output, 1, 1, 3, ' ', 0)
+	_, err = fmt.Fprintf(tw, "NODE\t%s\n", nodeUtilisationHeaders)
+	if err != nil {
+		return fmt.Errorf("failed to write header of console report %s: %w", title, err)
+	}
+	for _, testResult := range nodes {
+		_, err = fmt.Fprintf(tw, "%s\t%s\n", reports.QualifiedNodeName(testResult.Cluster, testResult.NodeName),
+			formatNodeUtilisation(testResult.Node.UtilisationBeforeTest))
+		if err != nil {
+			return fmt.Errorf("failed to write row of console report %s: %w", title, err)
+		}
+	}
+	err = tw.Flush()
+	if err != nil {
+		return fmt.Errorf("failed to flush console report %s: %w", title, err)
+	}
 	return nil
 }
 
+// writeNodeHealth writes the conditions, taints and recent warning events of the nodes which had any at test time.
+func (w *textWriter) writeNodeHealth(testSuiteResults []*reports.TestSuiteResult, output io.Writer) error {
+	nodes := []*reports.TestResult{}
+	visitedNodes := map[string]struct{}{}
+	for _, testSuiteResult := range testSuiteResults {
+		for _, testResult := range testSuiteResult.TestResults {
+			if testResult.Node == nil {
+				continue
+			}
+			if _, ok := visitedNodes[reports.QualifiedNodeName(testResult.Cluster, testResult.NodeName)]; ok {
+				continue
+			}
+			visitedNodes[reports.QualifiedNodeName(testResult.Cluster, testResult.NodeName)] = struct{}{}
+			if len(unhealthyConditions(testResult.Node)) > 0 || len(testResult.Node.Taints) > 0 ||
+				len(testResult.Node.WarningEvents) > 0 {
+				nodes = append(nodes, testResult)
+			}
+		}
+	}
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	title := "Node Conditions, Taints and Warning Events"
+	err := w.writeTitle(title, output)
+	if err != nil {
+		return fmt.Errorf("failed to print title of console report %s: %w", title, err)
+	}
+	tw := tabwriter.NewWriter(output, 1, 1, 3, ' ', 0)
+	_, err = fmt.Fprintln(tw, "NODE\tCONDITIONS\tTAINTS\tWARNING EVENTS\t")
+	if err != nil {
+		return fmt.Errorf("failed to write header of console report %s: %w", title, err)
+	}
+	for _, testResult := range nodes {
+		node := testResult.Node
+		taints := []string{}
+		for _, taint := range node.Taints {
+			taints = append(taints, taint.ToString())
+		}
+		events := []string{}
+		for _, event := range node.WarningEvents {
+			events = append(events, fmt.Sprintf("%s (x%d)", event.Reason, event.Count))
+		}
+		_, err = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\n", reports.QualifiedNodeName(testResult.Cluster, testResult.NodeName),
+			valueOrDash(strings.Join(unhealthyConditions(node), ", ")), valueOrDash(strings.Join(taints, ", ")),
+			valueOrDash(strings.Join(events, ", ")))
+		if err != nil {
+			return fmt.Errorf("failed to write row of console report %s: %w", title, err)
+		}
+	}
+	err = tw.Flush()
+	if err != nil {
+		return fmt.Errorf("failed to flush console report %s: %w", title, err)
+	}
+	return nil
+}
+
+func unhealthyConditions(node *evaluator.Node) []string {
+	conditions := []string{}
+	for _, condition := range node.Conditions {
+		if condition.Unhealthy() {
+			conditions = append(conditions, condition.Type+"="+condition.Status)
+		}
+	}
+	return conditions
+}
+
+func hasResourceUsage(testSuiteResult *reports.TestSuiteResult) bool {
+	for _, testResult := range testSuiteResult.TestResults {
+		if testResult.ResourceUsage != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func formatResourceUsage(usage *evaluator.ResourceUsage) string {
+	if usage == nil {
+		return "-\t-\t-\t-\t"
+	}
+	return fmt.Sprintf("%.3f cores\t%.2f%% (%s)\t%s\t%s / %s\t", usage.AverageCPUCores, usage.CPUThrottledPercentage,
+		usage.CPUThrottledTime, formatBytes(usage.PeakMemoryWorkingSetBytes), formatBytes(usage.NetworkRxBytes),
+		formatBytes(usage.NetworkTxBytes))
+}
+
+const nodeUtilisationHeaders = "NODE CPU\tNODE MEMORY\tNODE NETWORK RX / TX\tOTHER PODS\t"
+
+func formatNodeUtilisation(utilisation *evaluator.NodeUtilisation) string {
+	if utilisation == nil {
+		return "-\t-\t-\t-\t"
+	}
+	nonEvaluatorPodCount := "-"
+	if utilisation.NonEvaluatorPodCount != nil {
+		nonEvaluatorPodCount = fmt.Sprint(*utilisation.NonEvaluatorPodCount)
+	}
+	return fmt.Sprintf("%.2f cores (%.1f%%)\t%s (%.1f%%)\t%s/s / %s/s\t%s\t", utilisation.CPUCores, utilisation.CPUPercentage,
+		formatBytes(utilisation.MemoryWorkingSetBytes), utilisation.MemoryPercentage,
+		formatBytes(uint64(utilisation.NetworkRxBytesPerSecond)), formatBytes(uint64(utilisation.NetworkTxBytesPerSecond)),
+		nonEvaluatorPodCount)
+}
+
+func formatBytes(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%dB", bytes)
+	}
+	div, exp := uint64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
 func (w *textWriter) writeTitle(title string, output io.Writer) error {
 	verticalLine := strings.Repeat("=", len(title)+2)
 	_, err := fmt.Fprintf(output, "\n%s\n %s \n%s\n\n", verticalLine, title, verticalLine)
 	return err
 }
+
+func (w *textWriter) columnHeaders() string {
+	headers := ""
+	for _, column := range w.options.Columns {
+		headers += columnHeader(column) + "\t"
+	}
+	return headers
+}
+
+// columnHeader converts a node field (e.g. instanceType) or a label to a column header (e.g. INSTANCE TYPE).
+func columnHeader(column string) string {
+	header := strings.Builder{}
+	for i, r := range column {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			header.WriteRune(' ')
+		}
+		header.WriteRune(r)
+	}
+	return strings.ToUpper(header.String())
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -9,14 +9,26 @@ import (
 
 type Writer interface {
 	Write(results []*reports.TestSuiteResult, output io.Writer) error
+	WriteRegressions(regressions []*reports.Regression, output io.Writer) error
 }
 
-func ResolveWriter(writerType string) (Writer, error) {
+type Options struct {
+	// Columns are the node fields or labels added as columns to the report
+	Columns []string
+	// GroupBy is the node field or label by which the results are additionally aggregated
+	GroupBy string
+}
+
+func ResolveWriter(writerType string, options Options) (Writer, error) {
 	switch writerType {
 	case "text":
-		return &textWriter{}, nil
+		return &textWriter{
+			options: options,
+		}, nil
 	case "json":
-		return &jsonWriter{}, nil
+		return &jsonWriter{
+			options: options,
+		}, nil
 	default:
 		return nil, fmt.Errorf("unknown writer type: %s", writerType)
 	}
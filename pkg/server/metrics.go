@@ -0,0 +1,204 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type metric struct {
+	name       string
+	help       string
+	metricType string
+	samples    []metricSample
+}
+
+type metricSample struct {
+	labels map[string]string
+	value  float64
+}
+
+// writeMetrics exposes the results of the latest completed run in the Prometheus text format.
+func (s *Server) writeMetrics(w http.ResponseWriter, r *http.Request) {
+	metrics, err := s.collectMetrics()
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", metricsContentType)
+	err = writeMetrics(metrics, w)
+	if err != nil {
+		s.logger.Warnw("failed to write metrics", "error", err)
+	}
+}
+
+func (s *Server) collectMetrics() ([]*metric, error) {
+	runs, err := s.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	s.runLock.Lock()
+	runsTotal := &metric{
+		name:       "k8s_node_perf_runs_total",
+		help:       "Number of runs completed since the daemon started.",
+		metricType: "counter",
+	}
+	for _, status := range []RunStatus{RunStatusSucceeded, RunStatusFailed} {
+		runsTotal.samples = append(runsTotal.samples, metricSample{
+			labels: map[string]string{"status": string(status)},
+			value:  float64(s.runCounts[status]),
+		})
+	}
+	runInProgress := &metric{
+		name:       "k8s_node_perf_run_in_progress",
+		help:       "Whether a run is in progress.",
+		metricType: "gauge",
+		samples:    []metricSample{{value: boolToFloat(s.activeRun != nil)}},
+	}
+	s.runLock.Unlock()
+	metrics := []*metric{runsTotal, runInProgress}
+
+	var latestRun *Run
+	for _, run := range runs {
+		if run.Status != RunStatusRunning {
+			latestRun = run
+			break
+		}
+	}
+	if latestRun == nil {
+		return metrics, nil
+	}
+
+	metrics = append(metrics,
+		&metric{
+			name:       "k8s_node_perf_last_run_timestamp_seconds",
+			help:       "Time at which the latest completed run ended.",
+			metricType: "gauge",
+			samples:    []metricSample{{value: float64(latestRun.EndTime.UnixMilli()) / 1000}},
+		},
+		&metric{
+			name:       "k8s_node_perf_last_run_success",
+			help:       "Whether the latest completed run succeeded.",
+			metricType: "gauge",
+			samples:    []metricSample{{value: boolToFloat(latestRun.Status == RunStatusSucceeded)}},
+		},
+	)
+
+	averageLatency := &metric{
+		name:       "k8s_node_perf_average_latency_seconds",
+		help:       "Average latency of the requests to the test service on the node in the latest run.",
+		metricType: "gauge",
+	}
+	requests := &metric{
+		name:       "k8s_node_perf_requests",
+		help:       "Number of requests sent to the test service on the node in the latest run.",
+		metricType: "gauge",
+	}
+	failedRequestsRatio := &metric{
+		name:       "k8s_node_perf_failed_requests_ratio",
+		help:       "Fraction of the requests to the test service on the node which failed in the latest run.",
+		metricType: "gauge",
+	}
+	provisioningFailed := &metric{
+		name:       "k8s_node_perf_provisioning_failed",
+		help:       "Whether the test service could not be provisioned on the node in the latest run.",
+		metricType: "gauge",
+	}
+	for _, testSuiteResult := range latestRun.Results {
+		for _, testResult := range testSuiteResult.TestResults {
+			labels := nodeLabels(testResult.Cluster, testResult.NodeName)
+			labels["suite"] = testSuiteResult.Name
+			provisioningFailed.samples = append(provisioningFailed.samples, metricSample{
+				labels: labels,
+				value:  boolToFloat(testResult.ProvisioningFailed),
+			})
+			if testResult.ProvisioningFailed {
+				continue
+			}
+			averageLatency.samples = append(averageLatency.samples, metricSample{
+				labels: labels,
+				value:  testResult.AverageLatency.Seconds(),
+			})
+			requests.samples = append(requests.samples, metricSample{
+				labels: labels,
+				value:  float64(testResult.RequestCount),
+			})
+			failedRequestsRatio.samples = append(failedRequestsRatio.samples, metricSample{
+				labels: labels,
+				value:  testResult.FailedPercentage / 100,
+			})
+		}
+	}
+	nodePassed := &metric{
+		name:       "k8s_node_perf_node_passed",
+		help:       "Whether the node passed the thresholds in the latest run.",
+		metricType: "gauge",
+	}
+	for _, evaluation := range latestRun.Evaluations {
+		nodePassed.samples = append(nodePassed.samples, metricSample{
+			labels: nodeLabels(evaluation.Cluster, evaluation.NodeName),
+			value:  boolToFloat(evaluation.Passed),
+		})
+	}
+	return append(metrics, averageLatency, requests, failedRequestsRatio, provisioningFailed, nodePassed), nil
+}
+
+// nodeLabels identifies the node in the metrics. The cluster label is only added when multiple clusters are
+// evaluated together, since node names are only unique within a cluster.
+func nodeLabels(cluster string, nodeName string) map[string]string {
+	labels := map[string]string{"node": nodeName}
+	if cluster != "" {
+		labels["cluster"] = cluster
+	}
+	return labels
+}
+
+// writeMetrics writes the metrics in the Prometheus text exposition format.
+func writeMetrics(metrics []*metric, output io.Writer) error {
+	for _, m := range metrics {
+		if len(m.samples) == 0 {
+			continue
+		}
+		_, err := fmt.Fprintf(output, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.metricType)
+		if err != nil {
+			return err
+		}
+		for _, sample := range m.samples {
+			_, err = fmt.Fprintf(output, "%s%s %g\n", m.name, formatLabels(sample.labels), sample.value)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	names := []string{}
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := []string{}
+	for _, name := range names {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, labelValueReplacer.Replace(labels[name])))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
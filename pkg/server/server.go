@@ -0,0 +1,325 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/evaluator"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/reports"
+	"go.uber.org/zap"
+)
+
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+
+	shutdownTimeout = 30 * time.Second
+)
+
+// RunnerFactory creates a new test runner for each run so that each run gets its own run ID.
+type RunnerFactory func() evaluator.TestRunnerInterface
+
+// Server runs the evaluator on a schedule or on demand and serves the results over HTTP.
+type Server struct {
+	config        *config.Config
+	logger        *zap.SugaredLogger
+	store         Store
+	runnerFactory RunnerFactory
+	now           func() time.Time
+
+	runLock       sync.Mutex
+	activeRun     *Run
+	runCounts     map[RunStatus]int
+	runsWaitGroup sync.WaitGroup
+}
+
+func New(config *config.Config, logger *zap.SugaredLogger, store Store, runnerFactory RunnerFactory) *Server {
+	return &Server{
+		config:        config,
+		logger:        logger,
+		store:         store,
+		runnerFactory: runnerFactory,
+		now:           time.Now,
+		runCounts:     map[RunStatus]int{},
+	}
+}
+
+// ErrRunInProgress is returned when a run is triggered while another run is in progress.
+var ErrRunInProgress = errors.New("a run is already in progress")
+
+// Serve runs the evaluator at the configured interval and serves the HTTP API until the context is cancelled.
+// The first run starts immediately.
+func (s *Server) Serve(ctx context.Context) error {
+	// The address is bound before any run is started so that a mistake in it does not leave a run behind
+	listener, err := net.Listen("tcp", s.config.Serve.ListenAddress)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.config.Serve.ListenAddress, err)
+	}
+	runsCtx, cancelRuns := context.WithCancel(ctx)
+	defer cancelRuns()
+	httpServer := &http.Server{
+		Handler:           s.Handler(runsCtx),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		s.logger.Infow("serving evaluation results", "address", listener.Addr().String())
+		serveErr <- httpServer.Serve(listener)
+	}()
+
+	ticker := time.NewTicker(s.config.Serve.Interval)
+	defer ticker.Stop()
+	s.triggerScheduledRun(runsCtx)
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case err = <-serveErr:
+			break loop
+		case <-ticker.C:
+			s.triggerScheduledRun(runsCtx)
+		}
+	}
+	// The runs are stopped if serving failed as well, since their results can no longer be served
+	cancelRuns()
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+	defer cancel()
+	shutdownErr := httpServer.Shutdown(shutdownCtx)
+	// Runs clean up the resources they created once the context is cancelled
+	s.runsWaitGroup.Wait()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	if shutdownErr != nil {
+		return fmt.Errorf("failed to shutdown server: %w", shutdownErr)
+	}
+	return nil
+}
+
+func (s *Server) triggerScheduledRun(ctx context.Context) {
+	_, err := s.TriggerRun(ctx, TriggerSchedule)
+	if err != nil {
+		s.logger.Warnw("skipped scheduled run", "error", err)
+	}
+}
+
+// TriggerRun starts a run in the background and returns it, unless another run is in progress.
+func (s *Server) TriggerRun(ctx context.Context, trigger string) (*Run, error) {
+	s.runLock.Lock()
+	defer s.runLock.Unlock()
+	if s.activeRun != nil {
+		return nil, ErrRunInProgress
+	}
+	runner := s.runnerFactory()
+	run := &Run{
+		ID:        runner.RunID(),
+		Trigger:   trigger,
+		Status:    RunStatusRunning,
+		StartTime: s.now(),
+	}
+	err := s.store.Save(run)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save run: %w", err)
+	}
+	s.activeRun = run
+
+	s.runsWaitGroup.Add(1)
+	go func() {
+		defer s.runsWaitGroup.Done()
+		s.execute(ctx, runner, run)
+	}()
+	return run, nil
+}
+
+func (s *Server) execute(ctx context.Context, runner evaluator.TestRunnerInterface, run *Run) {
+	s.logger.Infow("starting run", "run", run.ID, "trigger", run.Trigger)
+	testSuites, runErr := runner.RunTest(ctx)
+
+	// The saved run is replaced instead of being updated since it might be read concurrently
+	completedRun := &Run{
+		ID:        run.ID,
+		Trigger:   run.Trigger,
+		Status:    RunStatusSucceeded,
+		StartTime: run.StartTime,
+		EndTime:   s.now(),
+		Results:   reports.CalculateTestSuiteResults(testSuites),
+	}
+	completedRun.Evaluations = reports.EvaluateThresholds(completedRun.Results, s.config.Thresholds)
+	if runErr != nil {
+		completedRun.Status = RunStatusFailed
+		completedRun.Error = runErr.Error()
+		s.logger.Errorw("run failed", "run", run.ID, "error", runErr)
+	} else {
+		s.logger.Infow("completed run", "run", run.ID, "duration", completedRun.EndTime.Sub(completedRun.StartTime))
+	}
+	err := s.store.Save(completedRun)
+	if err != nil {
+		s.logger.Errorw("failed to save run", "run", run.ID, "error", err)
+	}
+
+	s.runLock.Lock()
+	defer s.runLock.Unlock()
+	s.activeRun = nil
+	s.runCounts[completedRun.Status]++
+}
+
+// Handler returns the HTTP API of the server. Runs triggered through the API are bound to the context.
+func (s *Server) Handler(ctx context.Context) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /runs", s.listRuns)
+	mux.HandleFunc("POST /runs", func(w http.ResponseWriter, r *http.Request) {
+		s.triggerRun(ctx, w)
+	})
+	mux.HandleFunc("GET /runs/{id}", s.getRun)
+	mux.HandleFunc("GET /nodes/{name}", s.getNode)
+	mux.HandleFunc("GET /metrics", s.writeMetrics)
+	return mux
+}
+
+// RunSummary is a run without the results of the nodes.
+type RunSummary struct {
+	ID          string
+	Trigger     string
+	Status      RunStatus
+	Error       string
+	StartTime   time.Time
+	EndTime     time.Time
+	NodeCount   int
+	FailedNodes []string
+}
+
+func (s *Server) listRuns(w http.ResponseWriter, r *http.Request) {
+	runs, err := s.store.List()
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	summaries := []*RunSummary{}
+	for _, run := range runs {
+		summary := &RunSummary{
+			ID:          run.ID,
+			Trigger:     run.Trigger,
+			Status:      run.Status,
+			Error:       run.Error,
+			StartTime:   run.StartTime,
+			EndTime:     run.EndTime,
+			NodeCount:   len(run.Evaluations),
+			FailedNodes: []string{},
+		}
+		for _, evaluation := range run.Evaluations {
+			if !evaluation.Passed {
+				summary.FailedNodes = append(summary.FailedNodes, reports.QualifiedNodeName(evaluation.Cluster, evaluation.NodeName))
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	s.writeJSON(w, http.StatusOK, summaries)
+}
+
+func (s *Server) triggerRun(ctx context.Context, w http.ResponseWriter) {
+	run, err := s.TriggerRun(ctx, TriggerManual)
+	if errors.Is(err, ErrRunInProgress) {
+		s.writeError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Location", "/runs/"+run.ID)
+	s.writeJSON(w, http.StatusAccepted, run)
+}
+
+func (s *Server) getRun(w http.ResponseWriter, r *http.Request) {
+	run, err := s.store.Get(r.PathValue("id"))
+	if errors.Is(err, ErrRunNotFound) {
+		s.writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, run)
+}
+
+// NodeHistory is the results of a node across the runs, from the newest to the oldest.
+type NodeHistory struct {
+	Cluster string
+	Name    string
+	Runs    []*NodeRun
+}
+
+type NodeRun struct {
+	RunID      string
+	StartTime  time.Time
+	Evaluation *reports.NodeEvaluation
+	Results    map[string]*reports.TestResult
+}
+
+func (s *Server) getNode(w http.ResponseWriter, r *http.Request) {
+	runs, err := s.store.List()
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	// The cluster is required when multiple clusters are evaluated together, since node names are only unique
+	// within a cluster
+	history := &NodeHistory{
+		Cluster: r.URL.Query().Get("cluster"),
+		Name:    r.PathValue("name"),
+		Runs:    []*NodeRun{},
+	}
+	for _, run := range runs {
+		nodeRun := &NodeRun{
+			RunID:     run.ID,
+			StartTime: run.StartTime,
+			Results:   map[string]*reports.TestResult{},
+		}
+		for _, testSuiteResult := range run.Results {
+			for _, testResult := range testSuiteResult.TestResults {
+				if testResult.Cluster == history.Cluster && testResult.NodeName == history.Name {
+					nodeRun.Results[testSuiteResult.Name] = testResult
+				}
+			}
+		}
+		for _, evaluation := range run.Evaluations {
+			if evaluation.Cluster == history.Cluster && evaluation.NodeName == history.Name {
+				nodeRun.Evaluation = evaluation
+			}
+		}
+		if len(nodeRun.Results) > 0 {
+			history.Runs = append(history.Runs, nodeRun)
+		}
+	}
+	if len(history.Runs) == 0 {
+		s.writeError(w, http.StatusNotFound, fmt.Errorf("node %s was not evaluated in any of the runs",
+			reports.QualifiedNodeName(history.Cluster, history.Name)))
+		return
+	}
+	s.writeJSON(w, http.StatusOK, history)
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(body)
+	if err != nil {
+		s.logger.Warnw("failed to write response", "error", err)
+	}
+}
+
+func (s *Server) writeError(w http.ResponseWriter, status int, err error) {
+	s.writeJSON(w, status, map[string]string{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,293 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/evaluator"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type stubRunner struct {
+	release chan struct{}
+	// testSuites replace the default test suites when provided
+	testSuites []*evaluator.TestSuite
+}
+
+var _ evaluator.TestRunnerInterface = (*stubRunner)(nil)
+
+func (r *stubRunner) RunTest(ctx context.Context) ([]*evaluator.TestSuite, error) {
+	<-r.release
+	if r.testSuites != nil {
+		return r.testSuites, nil
+	}
+	return []*evaluator.TestSuite{
+		{
+			Name: "Ping Test",
+			Tests: []*evaluator.Test{
+				{
+					NodeName:           "node-a",
+					TotalRequestsCount: 10,
+					TotalLatency:       time.Second,
+				},
+				{
+					NodeName:                  "node-\"b\"",
+					ProvisioningFailed:        true,
+					ProvisioningFailureReason: "image pull error",
+				},
+			},
+		},
+	}, nil
+}
+
+func (r *stubRunner) RenderManifests(ctx context.Context) ([]runtime.Object, error) {
+	return nil, nil
+}
+
+func (r *stubRunner) ValidateManifests(ctx context.Context, objects []runtime.Object) error {
+	return nil
+}
+
+func (r *stubRunner) RunPreflightChecks(ctx context.Context) []*evaluator.PreflightCheck {
+	return nil
+}
+
+func (r *stubRunner) RunID() string {
+	return "stub-run"
+}
+
+func TestServer(t *testing.T) {
+	for name, newStore := range map[string]func(t *testing.T) Store{
+		"memory": func(t *testing.T) Store {
+			return NewMemoryStore(10)
+		},
+		"file": func(t *testing.T) Store {
+			store, err := NewFileStore(t.TempDir(), 10)
+			if err != nil {
+				t.Fatalf("failed to create file store: %v", err)
+			}
+			return store
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			runner := &stubRunner{release: make(chan struct{})}
+			s := New(&config.Config{
+				Thresholds: config.Thresholds{
+					FailOnProvisioningFailure: true,
+				},
+			}, zap.NewNop().Sugar(), newStore(t), func() evaluator.TestRunnerInterface {
+				return runner
+			})
+			ctx := context.Background()
+			httpServer := httptest.NewServer(s.Handler(ctx))
+			defer httpServer.Close()
+
+			resp := request(t, http.MethodPost, httpServer.URL+"/runs", http.StatusAccepted)
+			run := &Run{}
+			decode(t, resp, run)
+			if run.Status != RunStatusRunning || run.Trigger != TriggerManual {
+				t.Errorf("expected a running manual run, but got %+v", run)
+			}
+			request(t, http.MethodPost, httpServer.URL+"/runs", http.StatusConflict)
+
+			close(runner.release)
+			s.runsWaitGroup.Wait()
+
+			resp = request(t, http.MethodGet, httpServer.URL+"/runs/"+run.ID, http.StatusOK)
+			decode(t, resp, run)
+			if run.Status != RunStatusSucceeded || len(run.Results) != 1 || len(run.Evaluations) != 2 {
+				t.Errorf("expected a succeeded run with the results, but got %+v", run)
+			}
+			request(t, http.MethodGet, httpServer.URL+"/runs/unknown", http.StatusNotFound)
+
+			resp = request(t, http.MethodGet, httpServer.URL+"/runs", http.StatusOK)
+			summaries := []*RunSummary{}
+			decode(t, resp, &summaries)
+			if len(summaries) != 1 || len(summaries[0].FailedNodes) != 1 || summaries[0].FailedNodes[0] != "node-\"b\"" {
+				t.Errorf("expected a single run with a failed node, but got %+v", summaries)
+			}
+
+			resp = request(t, http.MethodGet, httpServer.URL+"/nodes/node-a", http.StatusOK)
+			history := &NodeHistory{}
+			decode(t, resp, history)
+			if len(history.Runs) != 1 || history.Runs[0].Results["Ping Test"].AverageLatency != 100*time.Millisecond ||
+				!history.Runs[0].Evaluation.Passed {
+				t.Errorf("expected the history of the node, but got %+v", history)
+			}
+			request(t, http.MethodGet, httpServer.URL+"/nodes/node-z", http.StatusNotFound)
+
+			resp = request(t, http.MethodGet, httpServer.URL+"/metrics", http.StatusOK)
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("failed to read metrics: %v", err)
+			}
+			for _, expected := range []string{
+				"# TYPE k8s_node_perf_runs_total counter",
+				`k8s_node_perf_runs_total{status="succeeded"} 1`,
+				`k8s_node_perf_average_latency_seconds{node="node-a",suite="Ping Test"} 0.1`,
+				`k8s_node_perf_provisioning_failed{node="node-\"b\"",suite="Ping Test"} 1`,
+				`k8s_node_perf_node_passed{node="node-a"} 1`,
+				"k8s_node_perf_run_in_progress 0",
+			} {
+				if !strings.Contains(string(body), expected) {
+					t.Errorf("expected metrics to contain %s, but got\n%s", expected, body)
+				}
+			}
+		})
+	}
+}
+
+func TestServerMultipleClusters(t *testing.T) {
+	runner := &stubRunner{
+		release: make(chan struct{}),
+		testSuites: []*evaluator.TestSuite{
+			{
+				Name: "Ping Test",
+				Tests: []*evaluator.Test{
+					{Cluster: "east", NodeName: "node-a", TotalRequestsCount: 10, TotalLatency: time.Second},
+					{Cluster: "west", NodeName: "node-a", ProvisioningFailed: true},
+				},
+			},
+		},
+	}
+	s := New(&config.Config{
+		Thresholds: config.Thresholds{
+			FailOnProvisioningFailure: true,
+		},
+	}, zap.NewNop().Sugar(), NewMemoryStore(10), func() evaluator.TestRunnerInterface {
+		return runner
+	})
+	ctx := context.Background()
+	httpServer := httptest.NewServer(s.Handler(ctx))
+	defer httpServer.Close()
+	request(t, http.MethodPost, httpServer.URL+"/runs", http.StatusAccepted)
+	close(runner.release)
+	s.runsWaitGroup.Wait()
+
+	resp := request(t, http.MethodGet, httpServer.URL+"/runs", http.StatusOK)
+	summaries := []*RunSummary{}
+	decode(t, resp, &summaries)
+	if len(summaries) != 1 || len(summaries[0].FailedNodes) != 1 || summaries[0].FailedNodes[0] != "west/node-a" {
+		t.Errorf("expected the failed node to be qualified by its cluster, but got %+v", summaries)
+	}
+
+	resp = request(t, http.MethodGet, httpServer.URL+"/nodes/node-a?cluster=east", http.StatusOK)
+	history := &NodeHistory{}
+	decode(t, resp, history)
+	if len(history.Runs) != 1 || history.Runs[0].Results["Ping Test"].Cluster != "east" || !history.Runs[0].Evaluation.Passed {
+		t.Errorf("expected the history of the node in the east cluster, but got %+v", history)
+	}
+	request(t, http.MethodGet, httpServer.URL+"/nodes/node-a", http.StatusNotFound)
+
+	resp = request(t, http.MethodGet, httpServer.URL+"/metrics", http.StatusOK)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read metrics: %v", err)
+	}
+	for _, expected := range []string{
+		`k8s_node_perf_average_latency_seconds{cluster="east",node="node-a",suite="Ping Test"} 0.1`,
+		`k8s_node_perf_provisioning_failed{cluster="west",node="node-a",suite="Ping Test"} 1`,
+		`k8s_node_perf_node_passed{cluster="east",node="node-a"} 1`,
+		`k8s_node_perf_node_passed{cluster="west",node="node-a"} 0`,
+	} {
+		if !strings.Contains(string(body), expected) {
+			t.Errorf("expected metrics to contain %s, but got\n%s", expected, body)
+		}
+	}
+}
+
+func TestServeListenFailure(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	runsCount := 0
+	s := New(&config.Config{
+		Serve: config.Serve{
+			ListenAddress: listener.Addr().String(),
+			Interval:      time.Hour,
+		},
+	}, zap.NewNop().Sugar(), NewMemoryStore(10), func() evaluator.TestRunnerInterface {
+		runsCount++
+		return &stubRunner{release: make(chan struct{})}
+	})
+	err = s.Serve(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "failed to listen") {
+		t.Errorf("expected serving on an address in use to fail, but got %v", err)
+	}
+	if runsCount != 0 {
+		t.Errorf("expected no runs to be started, but got %d", runsCount)
+	}
+}
+
+func TestFileStoreHistoryLimit(t *testing.T) {
+	directory := t.TempDir()
+	store, err := NewFileStore(directory, 2)
+	if err != nil {
+		t.Fatalf("failed to create file store: %v", err)
+	}
+	startTime := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, id := range []string{"run-1", "run-2", "run-3"} {
+		err = store.Save(&Run{ID: id, Status: RunStatusSucceeded, StartTime: startTime.Add(time.Duration(i) * time.Hour)})
+		if err != nil {
+			t.Fatalf("failed to save run %s: %v", id, err)
+		}
+	}
+
+	// Runs are read back from the files by a new store
+	store, err = NewFileStore(directory, 2)
+	if err != nil {
+		t.Fatalf("failed to create file store: %v", err)
+	}
+	runs, err := store.List()
+	if err != nil {
+		t.Fatalf("failed to list runs: %v", err)
+	}
+	if len(runs) != 2 || runs[0].ID != "run-3" || runs[1].ID != "run-2" {
+		t.Errorf("expected the 2 newest runs, but got %+v", runs)
+	}
+	_, err = store.Get("run-1")
+	if err != ErrRunNotFound {
+		t.Errorf("expected the oldest run to be removed, but got %v", err)
+	}
+	_, err = store.Get("../run-2")
+	if err != ErrRunNotFound {
+		t.Errorf("expected paths outside the store to be rejected, but got %v", err)
+	}
+}
+
+func request(t *testing.T, method string, url string, expectedStatus int) *http.Response {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to send %s %s: %v", method, url, err)
+	}
+	t.Cleanup(func() {
+		_ = resp.Body.Close()
+	})
+	if resp.StatusCode != expectedStatus {
+		t.Fatalf("expected status %d for %s %s, but got %d", expectedStatus, method, url, resp.StatusCode)
+	}
+	return resp
+}
+
+func decode(t *testing.T, resp *http.Response, v interface{}) {
+	err := json.NewDecoder(resp.Body).Decode(v)
+	if err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/reports"
+)
+
+type RunStatus string
+
+const (
+	RunStatusRunning   RunStatus = "running"
+	RunStatusSucceeded RunStatus = "succeeded"
+	RunStatusFailed    RunStatus = "failed"
+)
+
+// Run is a single evaluation of the cluster performed by the daemon.
+type Run struct {
+	ID          string
+	Trigger     string
+	Status      RunStatus
+	Error       string
+	StartTime   time.Time
+	EndTime     time.Time
+	Results     []*reports.TestSuiteResult
+	Evaluations []*reports.NodeEvaluation
+}
+
+// ErrRunNotFound is returned by the stores when there is no run with the requested ID.
+var ErrRunNotFound = errors.New("run not found")
+
+// Store keeps the runs performed by the daemon, dropping the oldest runs beyond the history limit.
+type Store interface {
+	Save(run *Run) error
+	Get(id string) (*Run, error)
+	// List returns the runs ordered from the newest to the oldest
+	List() ([]*Run, error)
+}
+
+type memoryStore struct {
+	lock         sync.RWMutex
+	historyLimit int
+	runs         []*Run
+}
+
+var _ Store = (*memoryStore)(nil)
+
+func NewMemoryStore(historyLimit int) Store {
+	return &memoryStore{
+		historyLimit: historyLimit,
+		runs:         []*Run{},
+	}
+}
+
+func (s *memoryStore) Save(run *Run) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for i, existingRun := range s.runs {
+		if existingRun.ID == run.ID {
+			s.runs[i] = run
+			return nil
+		}
+	}
+	s.runs = append([]*Run{run}, s.runs...)
+	sortRuns(s.runs)
+	if len(s.runs) > s.historyLimit {
+		s.runs = s.runs[:s.historyLimit]
+	}
+	return nil
+}
+
+func (s *memoryStore) Get(id string) (*Run, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	for _, run := range s.runs {
+		if run.ID == id {
+			return run, nil
+		}
+	}
+	return nil, ErrRunNotFound
+}
+
+func (s *memoryStore) List() ([]*Run, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return append([]*Run{}, s.runs...), nil
+}
+
+// fileStore persists each run as a JSON file in a directory so that the history survives restarts.
+type fileStore struct {
+	lock         sync.RWMutex
+	directory    string
+	historyLimit int
+}
+
+var _ Store = (*fileStore)(nil)
+
+const runFileExtension = ".json"
+
+func NewFileStore(directory string, historyLimit int) (Store, error) {
+	err := os.MkdirAll(directory, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+	return &fileStore{
+		directory:    directory,
+		historyLimit: historyLimit,
+	}, nil
+}
+
+func (s *fileStore) Save(run *Run) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	content, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to serialize run %s: %w", run.ID, err)
+	}
+	// Writing to a temporary file first avoids leaving a partially written run behind
+	tempFile := filepath.Join(s.directory, "."+run.ID+runFileExtension)
+	err = os.WriteFile(tempFile, content, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write run %s: %w", run.ID, err)
+	}
+	err = os.Rename(tempFile, s.runFile(run.ID))
+	if err != nil {
+		return fmt.Errorf("failed to write run %s: %w", run.ID, err)
+	}
+
+	runs, err := s.list()
+	if err != nil {
+		return err
+	}
+	for _, expiredRun := range runs[min(len(runs), s.historyLimit):] {
+		err = os.Remove(s.runFile(expiredRun.ID))
+		if err != nil {
+			return fmt.Errorf("failed to remove expired run %s: %w", expiredRun.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *fileStore) Get(id string) (*Run, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return nil, ErrRunNotFound
+	}
+	run, err := readRunFile(s.runFile(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrRunNotFound
+	}
+	return run, err
+}
+
+func (s *fileStore) List() ([]*Run, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	runs, err := s.list()
+	if err != nil {
+		return nil, err
+	}
+	return runs[:min(len(runs), s.historyLimit)], nil
+}
+
+func (s *fileStore) list() ([]*Run, error) {
+	entries, err := os.ReadDir(s.directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read store directory: %w", err)
+	}
+	runs := []*Run{}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || filepath.Ext(entry.Name()) != runFileExtension {
+			continue
+		}
+		run, err := readRunFile(filepath.Join(s.directory, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	sortRuns(runs)
+	return runs, nil
+}
+
+func (s *fileStore) runFile(id string) string {
+	return filepath.Join(s.directory, id+runFileExtension)
+}
+
+func readRunFile(file string) (*Run, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run file %s: %w", file, err)
+	}
+	run := &Run{}
+	err = json.Unmarshal(content, run)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse run file %s: %w", file, err)
+	}
+	return run, nil
+}
+
+func sortRuns(runs []*Run) {
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].StartTime.After(runs[j].StartTime)
+	})
+}
@@ -0,0 +1,321 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/k8s"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/testservice"
+	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// nodeLabel is the label added by the evaluator to the test services to identify the node
+const nodeLabel = "node"
+const ingressClassName = "simulated"
+const noisyNeighboursNamespace = "noisy-neighbours"
+
+// cluster is a simulated kubernetes cluster backed by an in-memory clientset. Test services
+// become ready immediately unless the node is configured to fail provisioning.
+type cluster struct {
+	k8s.Interface
+	clientset *fake.Clientset
+	nodes     map[string]*simulatedNode
+
+	hostsLock sync.RWMutex
+	hosts     map[string]string
+}
+
+var _ k8s.Interface = (*cluster)(nil)
+
+// simulatedNode serves the requests to the test service on a node.
+type simulatedNode struct {
+	spec    NodeSpec
+	handler http.Handler
+	usage   *resourceUsage
+}
+
+// New creates a kubernetes client for a simulated cluster and a http client which sends the
+// requests to the in-process test services of the simulated nodes.
+func New(scenario *Scenario) (k8s.Interface, *http.Client) {
+	objects := []runtime.Object{
+		&networkingv1.IngressClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: ingressClassName,
+				Annotations: map[string]string{
+					networkingv1.AnnotationIsDefaultIngressClass: "true",
+				},
+			},
+			Spec: networkingv1.IngressClassSpec{
+				Controller: "k8s-node-perf-evaluator/simulation",
+			},
+		},
+	}
+	nodes := map[string]*simulatedNode{}
+	for _, nodeSpec := range scenario.expandNodes() {
+		labels := map[string]string{
+			"kubernetes.io/hostname": nodeSpec.Name,
+		}
+		for key, value := range nodeSpec.Labels {
+			labels[key] = value
+		}
+		capacity := corev1.ResourceList{}
+		for resourceName, quantity := range nodeSpec.Capacity {
+			capacity[corev1.ResourceName(resourceName)] = resource.MustParse(quantity)
+		}
+		conditions := []corev1.NodeCondition{
+			{
+				Type:   corev1.NodeReady,
+				Status: corev1.ConditionTrue,
+			},
+			{
+				Type:   corev1.NodeMemoryPressure,
+				Status: corev1.ConditionFalse,
+			},
+			{
+				Type:   corev1.NodeDiskPressure,
+				Status: corev1.ConditionFalse,
+			},
+			{
+				Type:   corev1.NodePIDPressure,
+				Status: corev1.ConditionFalse,
+			},
+		}
+		for i := range conditions {
+			if status, ok := nodeSpec.Conditions[string(conditions[i].Type)]; ok {
+				conditions[i].Status = corev1.ConditionStatus(status)
+				conditions[i].Reason = "Simulated"
+			}
+		}
+		taints := []corev1.Taint{}
+		for _, taint := range nodeSpec.Taints {
+			taints = append(taints, corev1.Taint{
+				Key:    taint.Key,
+				Value:  taint.Value,
+				Effect: corev1.TaintEffect(taint.Effect),
+			})
+		}
+		for i, event := range nodeSpec.WarningEvents {
+			objects = append(objects, &corev1.Event{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%s.%d", nodeSpec.Name, i),
+					Namespace: metav1.NamespaceDefault,
+				},
+				InvolvedObject: corev1.ObjectReference{
+					Kind: "Node",
+					Name: nodeSpec.Name,
+				},
+				Type:          corev1.EventTypeWarning,
+				Reason:        event.Reason,
+				Message:       event.Message,
+				Count:         max(event.Count, 1),
+				LastTimestamp: metav1.Now(),
+			})
+		}
+		for i := 0; i < nodeSpec.NoisyNeighbours.Pods; i++ {
+			objects = append(objects, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%s-neighbour-%d", nodeSpec.Name, i),
+					Namespace: noisyNeighboursNamespace,
+				},
+				Spec: corev1.PodSpec{
+					NodeName: nodeSpec.Name,
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+				},
+			})
+		}
+		objects = append(objects, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              nodeSpec.Name,
+				Labels:            labels,
+				CreationTimestamp: metav1.Now(),
+			},
+			Spec: corev1.NodeSpec{
+				Taints: taints,
+			},
+			Status: corev1.NodeStatus{
+				NodeInfo: corev1.NodeSystemInfo{
+					KernelVersion:           nodeSpec.NodeInfo.KernelVersion,
+					OSImage:                 nodeSpec.NodeInfo.OSImage,
+					ContainerRuntimeVersion: nodeSpec.NodeInfo.ContainerRuntimeVersion,
+					KubeletVersion:          nodeSpec.NodeInfo.KubeletVersion,
+				},
+				Capacity:    capacity,
+				Allocatable: capacity,
+				Conditions:  conditions,
+			},
+		})
+
+		iterations := testservice.DefaultCPUIntensiveTaskIterations
+		if nodeSpec.CPUSlowdown > 0 {
+			iterations = int(float64(iterations) * nodeSpec.CPUSlowdown)
+		}
+		nodes[nodeSpec.Name] = &simulatedNode{
+			spec: nodeSpec,
+			handler: testservice.NewHandler(testservice.Options{
+				CPUIntensiveTaskIterations: iterations,
+			}),
+			usage: newResourceUsage(),
+		}
+	}
+
+	clientset := fake.NewClientset(objects...)
+	c := &cluster{
+		Interface: k8s.NewFromClientset(clientset),
+		clientset: clientset,
+		nodes:     nodes,
+		hosts:     map[string]string{},
+	}
+	clientset.PrependReactor("create", "deployments", c.reactToDeploymentCreation)
+	clientset.PrependReactor("create", "ingresses", c.reactToIngressCreation)
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", reactToAccessReview)
+
+	return c, &http.Client{
+		Timeout:   time.Minute,
+		Transport: &transport{cluster: c},
+	}
+}
+
+func (c *cluster) CreateDeployment(ctx context.Context, deployment *appsv1.Deployment, timeout time.Duration) (*appsv1.Deployment, error) {
+	d, err := c.Interface.CreateDeployment(ctx, deployment, timeout)
+	if err != nil {
+		return nil, err
+	}
+	node, ok := c.nodes[deployment.Spec.Template.GetLabels()[nodeLabel]]
+	if ok && node.spec.ProvisioningFailure != "" {
+		return nil, &k8s.ProvisioningError{
+			Kind:      "deployment",
+			Namespace: deployment.GetNamespace(),
+			Name:      deployment.GetName(),
+			Reason:    node.spec.ProvisioningFailure,
+			Err:       context.DeadlineExceeded,
+		}
+	}
+	// Pods are not created by a controller in the fake clientset
+	_, err = c.clientset.CoreV1().Pods(deployment.GetNamespace()).Create(ctx, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deployment.GetName() + "-0",
+			Namespace: deployment.GetNamespace(),
+			Labels:    deployment.Spec.Template.GetLabels(),
+		},
+		Spec: corev1.PodSpec{
+			NodeName: deployment.Spec.Template.GetLabels()[nodeLabel],
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pod of deployment %s: %w", deployment.GetName(), err)
+	}
+	return d, nil
+}
+
+func (c *cluster) DeleteNamespace(ctx context.Context, name string) error {
+	// Objects in the namespace are not garbage collected in the fake clientset
+	pods, err := c.clientset.CoreV1().Pods(name).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list pods in namespace %s: %w", name, err)
+	}
+	for _, pod := range pods.Items {
+		err = c.clientset.CoreV1().Pods(name).Delete(ctx, pod.GetName(), metav1.DeleteOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to delete pod %s: %w", pod.GetName(), err)
+		}
+	}
+	return c.Interface.DeleteNamespace(ctx, name)
+}
+
+func (c *cluster) reactToDeploymentCreation(action k8stesting.Action) (bool, runtime.Object, error) {
+	deployment := action.(k8stesting.CreateAction).GetObject().(*appsv1.Deployment)
+	node, ok := c.nodes[deployment.Spec.Template.GetLabels()[nodeLabel]]
+	if ok && node.spec.ProvisioningFailure != "" {
+		return false, nil, nil
+	}
+	deployment.Status.Replicas = 1
+	deployment.Status.AvailableReplicas = 1
+	deployment.Status.UpdatedReplicas = 1
+	return false, nil, nil
+}
+
+func (c *cluster) reactToIngressCreation(action k8stesting.Action) (bool, runtime.Object, error) {
+	ingress := action.(k8stesting.CreateAction).GetObject().(*networkingv1.Ingress)
+	ingress.Status.LoadBalancer.Ingress = []networkingv1.IngressLoadBalancerIngress{
+		{
+			IP: "127.0.0.1",
+		},
+	}
+
+	c.hostsLock.Lock()
+	defer c.hostsLock.Unlock()
+	for _, rule := range ingress.Spec.Rules {
+		c.hosts[rule.Host] = ingress.GetLabels()[nodeLabel]
+	}
+	return false, nil, nil
+}
+
+func reactToAccessReview(action k8stesting.Action) (bool, runtime.Object, error) {
+	review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+	review.Status.Allowed = true
+	return true, review, nil
+}
+
+func (c *cluster) resolveNode(host string) (*simulatedNode, error) {
+	c.hostsLock.RLock()
+	defer c.hostsLock.RUnlock()
+	nodeName, ok := c.hosts[host]
+	if !ok {
+		return nil, fmt.Errorf("no such host %s", host)
+	}
+	node, ok := c.nodes[nodeName]
+	if !ok {
+		return nil, fmt.Errorf("no such node %s", nodeName)
+	}
+	return node, nil
+}
+
+// transport sends the requests to the in-process test services of the simulated nodes.
+type transport struct {
+	cluster *cluster
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	node, err := t.cluster.resolveNode(req.URL.Hostname())
+	if err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(node.spec.Latency.Sample())
+	defer timer.Stop()
+	select {
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	case <-timer.C:
+	}
+
+	recorder := httptest.NewRecorder()
+	startTime := time.Now()
+	if node.spec.ErrorRate > 0 && rand.Float64() < node.spec.ErrorRate {
+		recorder.WriteHeader(http.StatusInternalServerError)
+	} else {
+		node.handler.ServeHTTP(recorder, req)
+	}
+	node.usage.record(time.Since(startTime), req, recorder.Body.Len())
+	resp := recorder.Result()
+	resp.Request = req
+	return resp, nil
+}
@@ -0,0 +1,184 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Scenario describes a simulated cluster and how the test service behaves on each of its nodes.
+type Scenario struct {
+	Nodes []NodeSpec `yaml:"nodes"`
+}
+
+type NodeSpec struct {
+	Name string `yaml:"name"`
+	// Count generates multiple nodes with the same spec, each suffixed with its index
+	Count   int               `yaml:"count"`
+	Labels  map[string]string `yaml:"labels"`
+	Latency Distribution      `yaml:"latency"`
+	// ErrorRate is the fraction of requests (between 0 and 1) which fail
+	ErrorRate float64 `yaml:"errorRate"`
+	// CPUSlowdown multiplies the work done for each CPU intensive task (e.g. 2 makes the node twice as slow)
+	CPUSlowdown float64 `yaml:"cpuSlowdown"`
+	// CPUThrottling is the fraction of CFS periods (between 0 and 1) in which the test service is throttled
+	CPUThrottling float64 `yaml:"cpuThrottling"`
+	// ProvisioningFailure makes provisioning the test service on the node fail with this reason
+	ProvisioningFailure string `yaml:"provisioningFailure"`
+
+	NodeInfo NodeInfo `yaml:"nodeInfo"`
+	// Conditions overrides the status of node conditions (e.g. MemoryPressure: "True")
+	Conditions    map[string]string `yaml:"conditions"`
+	Taints        []Taint           `yaml:"taints"`
+	WarningEvents []Event           `yaml:"warningEvents"`
+	// Capacity is the capacity of the node's resources (e.g. cpu: "4", memory: "16Gi"), which is allocatable as well
+	Capacity        map[string]string `yaml:"capacity"`
+	NoisyNeighbours NoisyNeighbours   `yaml:"noisyNeighbours"`
+}
+
+// NoisyNeighbours are other workloads sharing the node with the test service.
+type NoisyNeighbours struct {
+	Pods     int     `yaml:"pods"`
+	CPUCores float64 `yaml:"cpuCores"`
+	// Memory is the working set of the neighbours (e.g. "2Gi")
+	Memory                string  `yaml:"memory"`
+	NetworkBytesPerSecond float64 `yaml:"networkBytesPerSecond"`
+}
+
+type Taint struct {
+	Key    string `yaml:"key"`
+	Value  string `yaml:"value"`
+	Effect string `yaml:"effect"`
+}
+
+type Event struct {
+	Reason  string `yaml:"reason"`
+	Message string `yaml:"message"`
+	Count   int32  `yaml:"count"`
+}
+
+type NodeInfo struct {
+	KernelVersion           string `yaml:"kernelVersion"`
+	OSImage                 string `yaml:"osImage"`
+	ContainerRuntimeVersion string `yaml:"containerRuntimeVersion"`
+	KubeletVersion          string `yaml:"kubeletVersion"`
+}
+
+type DistributionType string
+
+const (
+	DistributionConstant    DistributionType = "constant"
+	DistributionUniform     DistributionType = "uniform"
+	DistributionNormal      DistributionType = "normal"
+	DistributionExponential DistributionType = "exponential"
+)
+
+// Distribution is the distribution of the additional latency added to each request.
+type Distribution struct {
+	Type   DistributionType `yaml:"type"`
+	Mean   time.Duration    `yaml:"mean"`
+	StdDev time.Duration    `yaml:"stdDev"`
+	Min    time.Duration    `yaml:"min"`
+	Max    time.Duration    `yaml:"max"`
+}
+
+func ReadScenario(s string) (*Scenario, error) {
+	scenarioFile, err := filepath.Abs(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the absolute path of scenario file: %w", err)
+	}
+	scenarioContent, err := os.ReadFile(scenarioFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario file: %w", err)
+	}
+	scenarioContent = []byte(os.ExpandEnv(string(scenarioContent)))
+
+	scenario := &Scenario{}
+	err = yaml.Unmarshal(scenarioContent, scenario)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse scenario file content: %w", err)
+	}
+	err = scenario.validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid scenario: %w", err)
+	}
+	return scenario, nil
+}
+
+func (scenario *Scenario) validate() error {
+	if len(scenario.Nodes) == 0 {
+		return fmt.Errorf("at least one node is required")
+	}
+	for _, node := range scenario.Nodes {
+		if node.Name == "" {
+			return fmt.Errorf("node name is required")
+		}
+		if node.ErrorRate < 0 || node.ErrorRate > 1 {
+			return fmt.Errorf("error rate of node %s should be between 0 and 1", node.Name)
+		}
+		if node.CPUThrottling < 0 || node.CPUThrottling > 1 {
+			return fmt.Errorf("cpu throttling of node %s should be between 0 and 1", node.Name)
+		}
+		for resourceName, quantity := range node.Capacity {
+			_, err := resource.ParseQuantity(quantity)
+			if err != nil {
+				return fmt.Errorf("invalid capacity of %s of node %s: %w", resourceName, node.Name, err)
+			}
+		}
+		if node.NoisyNeighbours.Memory != "" {
+			_, err := resource.ParseQuantity(node.NoisyNeighbours.Memory)
+			if err != nil {
+				return fmt.Errorf("invalid memory of the noisy neighbours of node %s: %w", node.Name, err)
+			}
+		}
+		switch node.Latency.Type {
+		case "", DistributionConstant, DistributionUniform, DistributionNormal, DistributionExponential:
+		default:
+			return fmt.Errorf("unknown latency distribution %s of node %s", node.Latency.Type, node.Name)
+		}
+	}
+	return nil
+}
+
+// expandNodes returns the specs of all the nodes in the scenario after expanding the node counts.
+func (scenario *Scenario) expandNodes() []NodeSpec {
+	nodes := []NodeSpec{}
+	for _, node := range scenario.Nodes {
+		if node.Count <= 1 {
+			nodes = append(nodes, node)
+			continue
+		}
+		for i := 1; i <= node.Count; i++ {
+			expandedNode := node
+			expandedNode.Name = fmt.Sprintf("%s-%d", node.Name, i)
+			nodes = append(nodes, expandedNode)
+		}
+	}
+	return nodes
+}
+
+// Sample returns a random duration following the distribution.
+func (d Distribution) Sample() time.Duration {
+	var sample float64
+	switch d.Type {
+	case DistributionUniform:
+		sample = float64(d.Min) + rand.Float64()*float64(d.Max-d.Min)
+	case DistributionNormal:
+		sample = float64(d.Mean) + rand.NormFloat64()*float64(d.StdDev)
+	case DistributionExponential:
+		sample = rand.ExpFloat64() * float64(d.Mean)
+	default:
+		sample = float64(d.Mean)
+	}
+	sample = math.Max(sample, float64(d.Min))
+	if d.Max > 0 {
+		sample = math.Min(sample, float64(d.Max))
+	}
+	return time.Duration(math.Max(sample, 0))
+}
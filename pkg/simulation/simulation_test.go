@@ -0,0 +1,135 @@
+package simulation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/evaluator"
+	"go.uber.org/zap"
+)
+
+func TestSimulatedRun(t *testing.T) {
+	scenario := &Scenario{
+		Nodes: []NodeSpec{
+			{
+				Name:          "healthy-node",
+				Count:         2,
+				CPUThrottling: 0.5,
+				Capacity: map[string]string{
+					"cpu":    "4",
+					"memory": "8Gi",
+				},
+				NoisyNeighbours: NoisyNeighbours{
+					Pods:     3,
+					CPUCores: 2,
+					Memory:   "1Gi",
+				},
+				Latency: Distribution{
+					Type: DistributionConstant,
+					Mean: 5 * time.Millisecond,
+				},
+			},
+			{
+				Name:      "failing-node",
+				ErrorRate: 1,
+			},
+			{
+				Name:                "broken-node",
+				ProvisioningFailure: "pod pending: Unschedulable",
+			},
+		},
+	}
+	k8sClient, httpClient := New(scenario)
+	runner := evaluator.NewTestRunnerWithClients(&config.Config{
+		Namespace: "k8s-node-perf-evaluation-services",
+		Ingress: config.Ingress{
+			ProtocolScheme:  "http",
+			HostnamePostfix: ".simulated",
+			PathPrefix:      "/",
+		},
+		Timeouts: config.Timeouts{
+			NamespaceDeletion: time.Second,
+			DeploymentReady:   time.Second,
+			IngressReady:      time.Second,
+		},
+		ResourceUsage: config.ResourceUsage{
+			SampleInterval: 10 * time.Millisecond,
+		},
+	}, zap.NewNop().Sugar(), k8sClient, httpClient)
+
+	testSuites, err := runner.RunTest(context.Background())
+	if err != nil {
+		t.Fatalf("failed to run test against the simulated cluster: %v", err)
+	}
+	if len(testSuites) != 2 {
+		t.Fatalf("expected 2 test suites, but got %d", len(testSuites))
+	}
+	for _, testSuite := range testSuites {
+		if len(testSuite.Tests) != 4 {
+			t.Fatalf("expected results for 4 nodes in %s, but got %d", testSuite.Name, len(testSuite.Tests))
+		}
+		for _, test := range testSuite.Tests {
+			switch test.NodeName {
+			case "healthy-node-1", "healthy-node-2":
+				if test.TotalFailedRequestsCount != 0 {
+					t.Errorf("expected no failed requests for %s in %s, but got %d", test.NodeName, testSuite.Name,
+						test.TotalFailedRequestsCount)
+				}
+				if test.TotalLatency < 5*time.Millisecond*time.Duration(test.TotalRequestsCount) {
+					t.Errorf("expected the simulated latency to be added for %s in %s", test.NodeName, testSuite.Name)
+				}
+				usage := test.ResourceUsage
+				if usage == nil {
+					t.Fatalf("expected resource usage to be collected for %s in %s", test.NodeName, testSuite.Name)
+				}
+				if usage.SampleCount < 2 || usage.PeakMemoryWorkingSetBytes == 0 || usage.NetworkTxBytes == 0 {
+					t.Errorf("expected memory and network usage for %s in %s, but got %+v", test.NodeName, testSuite.Name, usage)
+				}
+				if usage.CPUThrottledPercentage < 40 || usage.CPUThrottledPercentage > 60 {
+					t.Errorf("expected around 50%% cpu throttling for %s in %s, but got %.2f%%", test.NodeName, testSuite.Name,
+						usage.CPUThrottledPercentage)
+				}
+				for _, utilisation := range []*evaluator.NodeUtilisation{test.NodeUtilisation, test.Node.UtilisationBeforeTest} {
+					if utilisation == nil {
+						t.Fatalf("expected node utilisation to be collected for %s in %s", test.NodeName, testSuite.Name)
+					}
+					if utilisation.NonEvaluatorPodCount == nil || *utilisation.NonEvaluatorPodCount != 3 {
+						t.Errorf("expected 3 other pods on %s in %s, but got %v", test.NodeName, testSuite.Name,
+							utilisation.NonEvaluatorPodCount)
+					}
+					if utilisation.CPUPercentage < 45 || utilisation.MemoryPercentage < 18 {
+						t.Errorf("expected the noisy neighbours to be included in the utilisation of %s in %s, but got %+v",
+							test.NodeName, testSuite.Name, utilisation)
+					}
+				}
+			case "failing-node":
+				if test.TotalFailedRequestsCount != test.TotalRequestsCount {
+					t.Errorf("expected all requests to fail for %s in %s, but %d/%d failed", test.NodeName, testSuite.Name,
+						test.TotalFailedRequestsCount, test.TotalRequestsCount)
+				}
+			case "broken-node":
+				if !test.ProvisioningFailed || test.ProvisioningFailureReason != "pod pending: Unschedulable" {
+					t.Errorf("expected provisioning to fail for %s in %s, but got %+v", test.NodeName, testSuite.Name, test)
+				}
+			default:
+				t.Errorf("unexpected node %s in %s", test.NodeName, testSuite.Name)
+			}
+		}
+	}
+}
+
+func TestDistributionSample(t *testing.T) {
+	distribution := Distribution{
+		Type: DistributionUniform,
+		Min:  10 * time.Millisecond,
+		Max:  20 * time.Millisecond,
+	}
+	for i := 0; i < 100; i++ {
+		sample := distribution.Sample()
+		if sample < distribution.Min || sample > distribution.Max {
+			t.Fatalf("expected sample between %s and %s, but got %s", distribution.Min, distribution.Max, sample)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+const (
+	cfsPeriod                = 100 * time.Millisecond
+	testServiceContainerName = "test-service"
+	podMemoryWorkingSetBytes = 24 * 1024 * 1024
+	// systemMemoryWorkingSetBytes is the memory used by the operating system and the kubelet of a node
+	systemMemoryWorkingSetBytes = 512 * 1024 * 1024
+)
+
+// resourceUsage accumulates the simulated resource usage of the test service on a node from the requests it served.
+type resourceUsage struct {
+	startTime           time.Time
+	cpuUsageNanoSeconds atomic.Uint64
+	networkRxBytes      atomic.Uint64
+	networkTxBytes      atomic.Uint64
+	servedRequestsCount atomic.Uint64
+}
+
+func newResourceUsage() *resourceUsage {
+	return &resourceUsage{
+		startTime: time.Now(),
+	}
+}
+
+func (usage *resourceUsage) record(cpuTime time.Duration, req *http.Request, responseSize int) {
+	usage.cpuUsageNanoSeconds.Add(uint64(cpuTime.Nanoseconds()))
+	usage.networkRxBytes.Add(uint64(max(req.ContentLength, 0)) + uint64(len(req.URL.RequestURI())))
+	usage.networkTxBytes.Add(uint64(responseSize))
+	usage.servedRequestsCount.Add(1)
+}
+
+func (c *cluster) GetNodeStatsSummary(ctx context.Context, nodeName string) (*k8s.StatsSummary, error) {
+	node, ok := c.nodes[nodeName]
+	if !ok {
+		return nil, fmt.Errorf("no such node %s", nodeName)
+	}
+	pods, err := c.nodePods(ctx, nodeName)
+	if err != nil {
+		return nil, err
+	}
+
+	now := metav1.Now()
+	cpuUsage := node.usage.cpuUsageNanoSeconds.Load()
+	rxBytes := node.usage.networkRxBytes.Load()
+	txBytes := node.usage.networkTxBytes.Load()
+	// The working set grows with the requests served up to an additional 1Mi
+	workingSetBytes := uint64(podMemoryWorkingSetBytes) + min(node.usage.servedRequestsCount.Load(), 1024)*1024
+	summary := &k8s.StatsSummary{
+		Node: node.nodeStats(now, cpuUsage, rxBytes, txBytes, workingSetBytes*uint64(len(pods))),
+		Pods: []k8s.PodStats{},
+	}
+	for _, pod := range pods {
+		summary.Pods = append(summary.Pods, k8s.PodStats{
+			PodRef: k8s.PodReference{
+				Name:      pod.GetName(),
+				Namespace: pod.GetNamespace(),
+				UID:       string(pod.GetUID()),
+			},
+			CPU: &k8s.CPUStats{
+				Time:                 now,
+				UsageCoreNanoSeconds: &cpuUsage,
+			},
+			Memory: &k8s.MemoryStats{
+				Time:            now,
+				WorkingSetBytes: &workingSetBytes,
+			},
+			Network: &k8s.NetworkStats{
+				Time: now,
+				Interfaces: []k8s.InterfaceStats{
+					{
+						Name:    "eth0",
+						RxBytes: &rxBytes,
+						TxBytes: &txBytes,
+					},
+				},
+			},
+		})
+	}
+	return summary, nil
+}
+
+// nodeStats adds the usage of the noisy neighbours and the system to the usage of the test service.
+func (node *simulatedNode) nodeStats(now metav1.Time, cpuUsage uint64, rxBytes uint64, txBytes uint64,
+	podsWorkingSetBytes uint64) k8s.NodeStats {
+	elapsed := time.Since(node.usage.startTime)
+	neighbours := node.spec.NoisyNeighbours
+	nodeCPUUsage := cpuUsage + uint64(neighbours.CPUCores*float64(elapsed.Nanoseconds()))
+	neighboursNetworkBytes := uint64(neighbours.NetworkBytesPerSecond * elapsed.Seconds())
+	nodeRxBytes := rxBytes + neighboursNetworkBytes
+	nodeTxBytes := txBytes + neighboursNetworkBytes
+	nodeWorkingSetBytes := systemMemoryWorkingSetBytes + podsWorkingSetBytes
+	if neighbours.Memory != "" {
+		neighboursMemory := resource.MustParse(neighbours.Memory)
+		nodeWorkingSetBytes += uint64(neighboursMemory.Value())
+	}
+	return k8s.NodeStats{
+		NodeName: node.spec.Name,
+		CPU: &k8s.CPUStats{
+			Time:                 now,
+			UsageCoreNanoSeconds: &nodeCPUUsage,
+		},
+		Memory: &k8s.MemoryStats{
+			Time:            now,
+			WorkingSetBytes: &nodeWorkingSetBytes,
+		},
+		Network: &k8s.NetworkStats{
+			Time: now,
+			Interfaces: []k8s.InterfaceStats{
+				{
+					Name:    "eth0",
+					RxBytes: &nodeRxBytes,
+					TxBytes: &nodeTxBytes,
+				},
+			},
+		},
+	}
+}
+
+func (c *cluster) GetNodeCPUThrottling(ctx context.Context, nodeName string) (map[k8s.ContainerKey]*k8s.CPUThrottling, error) {
+	node, ok := c.nodes[nodeName]
+	if !ok {
+		return nil, fmt.Errorf("no such node %s", nodeName)
+	}
+	pods, err := c.nodePods(ctx, nodeName)
+	if err != nil {
+		return nil, err
+	}
+
+	periods := float64(time.Since(node.usage.startTime)) / float64(cfsPeriod)
+	throttling := map[k8s.ContainerKey]*k8s.CPUThrottling{}
+	for _, pod := range pods {
+		throttling[k8s.ContainerKey{
+			Namespace: pod.GetNamespace(),
+			Pod:       pod.GetName(),
+			Container: testServiceContainerName,
+		}] = &k8s.CPUThrottling{
+			Periods:          periods,
+			ThrottledPeriods: periods * node.spec.CPUThrottling,
+			ThrottledSeconds: periods * node.spec.CPUThrottling * cfsPeriod.Seconds(),
+		}
+	}
+	return throttling, nil
+}
+
+// nodePods returns the test service pods on the node.
+func (c *cluster) nodePods(ctx context.Context, nodeName string) ([]corev1.Pod, error) {
+	pods, err := c.clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods of node %s: %w", nodeName, err)
+	}
+	nodePods := []corev1.Pod{}
+	for _, pod := range pods.Items {
+		// The fake clientset does not filter by field selectors
+		if pod.Spec.NodeName == nodeName && pod.GetNamespace() != noisyNeighboursNamespace {
+			nodePods = append(nodePods, pod)
+		}
+	}
+	return nodePods, nil
+}
@@ -0,0 +1,56 @@
+package testservice
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+)
+
+// DefaultCPUIntensiveTaskIterations is the number of iterations performed for each CPU intensive task request.
+var DefaultCPUIntensiveTaskIterations = int(math.Pow(10, 5))
+
+type Options struct {
+	CPUIntensiveTaskIterations int
+}
+
+// NewHandler creates the handler serving all the endpoints of the test service.
+func NewHandler(options Options) http.Handler {
+	if options.CPUIntensiveTaskIterations == 0 {
+		options.CPUIntensiveTaskIterations = DefaultCPUIntensiveTaskIterations
+	}
+
+	serviceMux := http.NewServeMux()
+	serviceMux.Handle("/", http.HandlerFunc(handleUnknownPath))
+	serviceMux.Handle("/ping", http.HandlerFunc(handlePing))
+	serviceMux.Handle("/cpu-intensive-task", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleCPUIntensiveTask(w, r, options.CPUIntensiveTaskIterations)
+	}))
+	return serviceMux
+}
+
+func handleUnknownPath(w http.ResponseWriter, _ *http.Request) {
+	_, err := fmt.Fprintf(w, "{\"status\":\"not_found\"}")
+	if err != nil {
+		log.Printf("Failed to write response to ping: %v", err)
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func handlePing(w http.ResponseWriter, _ *http.Request) {
+	_, err := fmt.Fprintf(w, "{\"status\":\"success\"}")
+	if err != nil {
+		log.Printf("Failed to write response to ping: %v", err)
+	}
+}
+
+func handleCPUIntensiveTask(w http.ResponseWriter, _ *http.Request, iterations int) {
+	var result float64
+	for i := 0; i < iterations; i++ {
+		result += math.Tan(float64(i)) * math.Atan(float64(i))
+	}
+	_, err := fmt.Fprintf(w, "{\"status\":\"success\",\"result\":\"%.2f\"}", result)
+	if err != nil {
+		log.Printf("Failed to write response to CPU intensive task: %v", err)
+	}
+}
@@ -0,0 +1,341 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/evaluator"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/k8s"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/reports"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const (
+	// UnevaluatedTaintKey is the key of the taint added to the new nodes until they pass the evaluation
+	UnevaluatedTaintKey = "k8s-node-perf-evaluator/unevaluated"
+
+	ReasonEvaluationPassed = "NodePerfEvaluationPassed"
+	ReasonEvaluationFailed = "NodePerfEvaluationFailed"
+	ReasonEvaluationError  = "NodePerfEvaluationError"
+
+	// Events of cluster scoped objects are created in the default namespace
+	eventNamespace = "default"
+	eventSource    = "k8s-node-perf-evaluator"
+)
+
+var unevaluatedTaint = corev1.Taint{
+	Key:    UnevaluatedTaintKey,
+	Value:  "true",
+	Effect: corev1.TaintEffectNoSchedule,
+}
+
+// RunnerFactory creates the test runner for evaluating the nodes selected by the config.
+type RunnerFactory func(config *config.Config) evaluator.TestRunnerInterface
+
+// Watcher evaluates the nodes which join the cluster once they become ready. The nodes which already
+// exist when the watcher starts are not evaluated.
+type Watcher struct {
+	config        *config.Config
+	logger        *zap.SugaredLogger
+	k8sClient     k8s.Interface
+	runnerFactory RunnerFactory
+	now           func() time.Time
+
+	labelSelector labels.Selector
+	knownNodes    map[string]bool
+	// pendingNodes are the new nodes which are yet to be evaluated
+	pendingNodes map[string]*corev1.Node
+}
+
+func New(config *config.Config, logger *zap.SugaredLogger, k8sClient k8s.Interface, runnerFactory RunnerFactory) *Watcher {
+	return &Watcher{
+		config:        config,
+		logger:        logger,
+		k8sClient:     k8sClient,
+		runnerFactory: runnerFactory,
+		now:           time.Now,
+		knownNodes:    map[string]bool{},
+		pendingNodes:  map[string]*corev1.Node{},
+	}
+}
+
+// Watch evaluates the new nodes until the context is cancelled. The nodes which become ready within the
+// batch delay of each other are evaluated together, and only a single evaluation is run at a time.
+func (w *Watcher) Watch(ctx context.Context) error {
+	var err error
+	w.labelSelector, err = labels.Parse(w.config.NodeSelector.LabelSelector)
+	if err != nil {
+		return fmt.Errorf("failed to parse node label selector: %w", err)
+	}
+	selector := k8s.Selector{
+		LabelSelector: w.config.NodeSelector.LabelSelector,
+		FieldSelector: w.config.NodeSelector.FieldSelector,
+	}
+	nodes, err := w.k8sClient.ListNodes(ctx, selector)
+	if err != nil {
+		return fmt.Errorf("failed to list the nodes in the cluster: %w", err)
+	}
+	var batchTimer <-chan time.Time
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		w.knownNodes[node.GetName()] = true
+		// The evaluation of the nodes which are still tainted was interrupted (e.g. by a restart of the watcher)
+		if isUnevaluated(node) && w.isSelected(node) {
+			w.pendingNodes[node.GetName()] = node
+			w.logger.Infow("existing node has not passed the evaluation yet", "node", node.GetName())
+			if isReady(node) && batchTimer == nil {
+				batchTimer = time.After(w.config.Watch.BatchDelay)
+			}
+		}
+	}
+	resourceVersion := nodes.GetResourceVersion()
+	w.logger.Infow("watching for new nodes", "existingNodes", len(w.knownNodes), "pendingNodes", len(w.pendingNodes))
+
+	var evaluationDone chan struct{}
+	startEvaluation := func() {
+		readyNodes := w.takeReadyNodes()
+		if len(readyNodes) == 0 {
+			return
+		}
+		evaluationDone = make(chan struct{})
+		go func(done chan struct{}) {
+			defer close(done)
+			w.evaluate(ctx, readyNodes)
+		}(evaluationDone)
+	}
+
+	for {
+		nodeWatch, err := w.k8sClient.WatchNodes(ctx, selector, resourceVersion)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			return fmt.Errorf("failed to watch the nodes in the cluster: %w", err)
+		}
+		expired := false
+		for !expired && ctx.Err() == nil {
+			select {
+			case <-ctx.Done():
+			case event, ok := <-nodeWatch.ResultChan():
+				if !ok || event.Type == watch.Error {
+					expired = true
+					break
+				}
+				node, isNode := event.Object.(*corev1.Node)
+				if !isNode {
+					continue
+				}
+				resourceVersion = node.GetResourceVersion()
+				if event.Type == watch.Deleted {
+					delete(w.knownNodes, node.GetName())
+					delete(w.pendingNodes, node.GetName())
+				} else if w.handleNode(ctx, node) && batchTimer == nil {
+					batchTimer = time.After(w.config.Watch.BatchDelay)
+				}
+			case <-batchTimer:
+				batchTimer = nil
+				if evaluationDone == nil {
+					startEvaluation()
+				}
+			case <-evaluationDone:
+				evaluationDone = nil
+				// The nodes which became ready during the evaluation have already waited for the batch delay
+				if batchTimer == nil {
+					startEvaluation()
+				}
+			}
+		}
+		nodeWatch.Stop()
+		if ctx.Err() != nil {
+			break
+		}
+
+		// The watch was closed or expired, and therefore the nodes are listed again before re-watching
+		nodes, err = w.k8sClient.ListNodes(ctx, selector)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			return fmt.Errorf("failed to list the nodes in the cluster: %w", err)
+		}
+		for i := range nodes.Items {
+			if w.handleNode(ctx, &nodes.Items[i]) && batchTimer == nil {
+				batchTimer = time.After(w.config.Watch.BatchDelay)
+			}
+		}
+		resourceVersion = nodes.GetResourceVersion()
+	}
+
+	// The evaluation cleans up the resources it created once the context is cancelled
+	if evaluationDone != nil {
+		<-evaluationDone
+	}
+	return nil
+}
+
+// handleNode tracks the new nodes and returns whether the node is a new node ready to be evaluated.
+func (w *Watcher) handleNode(ctx context.Context, node *corev1.Node) bool {
+	name := node.GetName()
+	if !w.isSelected(node) {
+		return false
+	}
+	if !w.knownNodes[name] {
+		w.knownNodes[name] = true
+		w.pendingNodes[name] = node
+		w.logger.Infow("new node joined the cluster", "node", name)
+		if w.config.Watch.TaintUnevaluated {
+			err := w.k8sClient.TaintNode(ctx, name, []corev1.Taint{unevaluatedTaint})
+			if err != nil {
+				w.logger.Warnw("failed to taint unevaluated node", "node", name, "error", err)
+			}
+		}
+	}
+	if _, pending := w.pendingNodes[name]; !pending {
+		return false
+	}
+	w.pendingNodes[name] = node
+	return isReady(node)
+}
+
+func (w *Watcher) isSelected(node *corev1.Node) bool {
+	return w.labelSelector.Matches(labels.Set(node.GetLabels())) &&
+		(len(w.config.NodeSelector.Names) == 0 || slices.Contains(w.config.NodeSelector.Names, node.GetName()))
+}
+
+func (w *Watcher) takeReadyNodes() []*corev1.Node {
+	readyNodes := []*corev1.Node{}
+	for name, node := range w.pendingNodes {
+		if isReady(node) {
+			readyNodes = append(readyNodes, node)
+			delete(w.pendingNodes, name)
+		}
+	}
+	return readyNodes
+}
+
+func (w *Watcher) evaluate(ctx context.Context, nodes []*corev1.Node) {
+	runConfig := *w.config
+	runConfig.NodeSelector.Names = []string{}
+	for _, node := range nodes {
+		runConfig.NodeSelector.Names = append(runConfig.NodeSelector.Names, node.GetName())
+	}
+	if w.config.Watch.TaintUnevaluated || slices.ContainsFunc(nodes, isUnevaluated) {
+		// The taint would otherwise prevent the test services from being scheduled using the node affinity
+		runConfig.TestService.Scheduling.Tolerations = append(slices.Clone(w.config.TestService.Scheduling.Tolerations),
+			config.Toleration{
+				Key:      unevaluatedTaint.Key,
+				Operator: string(corev1.TolerationOpExists),
+				Effect:   string(unevaluatedTaint.Effect),
+			})
+	}
+	w.logger.Infow("evaluating new nodes", "nodes", runConfig.NodeSelector.Names)
+
+	testSuites, runErr := w.runnerFactory(&runConfig).RunTest(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+	if runErr != nil {
+		w.logger.Errorw("failed to evaluate new nodes", "nodes", runConfig.NodeSelector.Names, "error", runErr)
+		for _, node := range nodes {
+			w.recordEvent(ctx, node, corev1.EventTypeWarning, ReasonEvaluationError,
+				fmt.Sprintf("Performance evaluation could not be completed: %v", runErr))
+		}
+		return
+	}
+
+	testSuiteResults := reports.CalculateTestSuiteResults(testSuites)
+	evaluations := reports.EvaluateThresholds(testSuiteResults, w.config.Thresholds)
+	for _, node := range nodes {
+		evaluation := findEvaluation(evaluations, node.GetName())
+		if evaluation == nil {
+			w.logger.Warnw("new node was not evaluated", "node", node.GetName())
+			continue
+		}
+		if !evaluation.Passed {
+			w.logger.Warnw("new node failed the evaluation", "node", node.GetName(), "violations", evaluation.Violations)
+			w.recordEvent(ctx, node, corev1.EventTypeWarning, ReasonEvaluationFailed,
+				fmt.Sprintf("Failed the performance evaluation: %s", strings.Join(evaluation.Violations, "; ")))
+			continue
+		}
+		w.logger.Infow("new node passed the evaluation", "node", node.GetName())
+		w.recordEvent(ctx, node, corev1.EventTypeNormal, ReasonEvaluationPassed,
+			fmt.Sprintf("Passed the performance evaluation: %s", summarizeResults(testSuiteResults, node.GetName())))
+		if w.config.Watch.TaintUnevaluated || isUnevaluated(node) {
+			err := w.k8sClient.UntaintNode(ctx, node.GetName(), unevaluatedTaint)
+			if err != nil {
+				w.logger.Warnw("failed to remove the unevaluated taint", "node", node.GetName(), "error", err)
+			}
+		}
+	}
+}
+
+func (w *Watcher) recordEvent(ctx context.Context, node *corev1.Node, eventType string, reason string, message string) {
+	now := metav1.NewTime(w.now())
+	_, err := w.k8sClient.CreateEvent(ctx, &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", node.GetName(), now.UnixNano()),
+			Namespace: eventNamespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Node",
+			Name:       node.GetName(),
+			UID:        node.GetUID(),
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		Source:         corev1.EventSource{Component: eventSource},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	})
+	if err != nil {
+		w.logger.Warnw("failed to record event", "node", node.GetName(), "reason", reason, "error", err)
+	}
+}
+
+func findEvaluation(evaluations []*reports.NodeEvaluation, nodeName string) *reports.NodeEvaluation {
+	for _, evaluation := range evaluations {
+		if evaluation.NodeName == nodeName {
+			return evaluation
+		}
+	}
+	return nil
+}
+
+func summarizeResults(testSuiteResults []*reports.TestSuiteResult, nodeName string) string {
+	summaries := []string{}
+	for _, testSuiteResult := range testSuiteResults {
+		for _, testResult := range testSuiteResult.TestResults {
+			if testResult.NodeName == nodeName {
+				summaries = append(summaries, fmt.Sprintf("%s average latency %s", testSuiteResult.Name,
+					testResult.AverageLatency))
+			}
+		}
+	}
+	return strings.Join(summaries, ", ")
+}
+
+func isUnevaluated(node *corev1.Node) bool {
+	return slices.ContainsFunc(node.Spec.Taints, func(taint corev1.Taint) bool {
+		return taint.Key == UnevaluatedTaintKey
+	})
+}
+
+func isReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,242 @@
+package watcher
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/config"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/evaluator"
+	"github.com/nadundesilva/k8s-node-perf-evaluator/pkg/k8s"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type stubRunner struct {
+	config *config.Config
+}
+
+var _ evaluator.TestRunnerInterface = (*stubRunner)(nil)
+
+// RunTest reports node-slow to be slower than the threshold, and every other node to be fast.
+func (r *stubRunner) RunTest(ctx context.Context) ([]*evaluator.TestSuite, error) {
+	testSuite := &evaluator.TestSuite{Name: "Ping Test"}
+	for _, nodeName := range r.config.NodeSelector.Names {
+		latency := time.Second
+		if nodeName == "node-slow" {
+			latency = 30 * time.Second
+		}
+		testSuite.Tests = append(testSuite.Tests, &evaluator.Test{
+			NodeName:           nodeName,
+			TotalRequestsCount: 10,
+			TotalLatency:       latency,
+		})
+	}
+	return []*evaluator.TestSuite{testSuite}, nil
+}
+
+func (r *stubRunner) RenderManifests(ctx context.Context) ([]runtime.Object, error) {
+	return nil, nil
+}
+
+func (r *stubRunner) ValidateManifests(ctx context.Context, objects []runtime.Object) error {
+	return nil
+}
+
+func (r *stubRunner) RunPreflightChecks(ctx context.Context) []*evaluator.PreflightCheck {
+	return nil
+}
+
+func (r *stubRunner) RunID() string {
+	return "stub-run"
+}
+
+func newNode(name string, ready bool) *corev1.Node {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"pool": "general"},
+		},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}},
+		},
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	clientset := fake.NewClientset(newNode("node-existing", true))
+	runLock := sync.Mutex{}
+	runs := [][]string{}
+	w := New(&config.Config{
+		NodeSelector: config.Selector{LabelSelector: "pool=general"},
+		Thresholds: config.Thresholds{
+			Suites: []config.SuiteThreshold{{MaxAverageLatency: time.Second}},
+		},
+		Watch: config.Watch{
+			TaintUnevaluated: true,
+			BatchDelay:       10 * time.Millisecond,
+		},
+	}, zap.NewNop().Sugar(), k8s.NewFromClientset(clientset), func(c *config.Config) evaluator.TestRunnerInterface {
+		runLock.Lock()
+		defer runLock.Unlock()
+		runs = append(runs, c.NodeSelector.Names)
+		return &stubRunner{config: c}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	watchErr := make(chan error, 1)
+	go func() {
+		watchErr <- w.Watch(ctx)
+	}()
+	waitFor(t, "the nodes to be watched", func() bool {
+		for _, action := range clientset.Actions() {
+			if action.GetVerb() == "watch" {
+				return true
+			}
+		}
+		return false
+	})
+
+	nodes := clientset.CoreV1().Nodes()
+	for _, node := range []*corev1.Node{newNode("node-fast", false), newNode("node-slow", false)} {
+		_, err := nodes.Create(ctx, node, metav1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("failed to create node %s: %v", node.GetName(), err)
+		}
+	}
+	otherPoolNode := newNode("node-other-pool", true)
+	otherPoolNode.Labels["pool"] = "gpu"
+	_, err := nodes.Create(ctx, otherPoolNode, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("failed to create node %s: %v", otherPoolNode.GetName(), err)
+	}
+	waitFor(t, "the new nodes to be tainted", func() bool {
+		return hasUnevaluatedTaint(t, clientset, "node-fast") && hasUnevaluatedTaint(t, clientset, "node-slow")
+	})
+
+	for _, name := range []string{"node-fast", "node-slow"} {
+		node, err := nodes.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get node %s: %v", name, err)
+		}
+		node.Status.Conditions[0].Status = corev1.ConditionTrue
+		_, err = nodes.Update(ctx, node, metav1.UpdateOptions{})
+		if err != nil {
+			t.Fatalf("failed to update node %s: %v", name, err)
+		}
+	}
+	waitFor(t, "the events to be recorded", func() bool {
+		events, err := clientset.CoreV1().Events(eventNamespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			t.Fatalf("failed to list events: %v", err)
+		}
+		return len(events.Items) == 2
+	})
+	cancel()
+	err = <-watchErr
+	if err != nil {
+		t.Fatalf("failed to watch nodes: %v", err)
+	}
+
+	evaluatedNodes := 0
+	for _, run := range runs {
+		evaluatedNodes += len(run)
+	}
+	if evaluatedNodes != 2 {
+		t.Errorf("expected only the new nodes to be evaluated once, but got %v", runs)
+	}
+	if hasUnevaluatedTaint(t, clientset, "node-fast") {
+		t.Errorf("expected the taint to be removed from the node which passed")
+	}
+	if !hasUnevaluatedTaint(t, clientset, "node-slow") {
+		t.Errorf("expected the taint to be kept on the node which failed")
+	}
+	if hasUnevaluatedTaint(t, clientset, "node-other-pool") {
+		t.Errorf("expected nodes not matching the selector to be ignored")
+	}
+	events, err := clientset.CoreV1().Events(eventNamespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list events: %v", err)
+	}
+	reasons := map[string]string{}
+	for _, event := range events.Items {
+		reasons[event.InvolvedObject.Name] = event.Reason
+	}
+	if reasons["node-fast"] != ReasonEvaluationPassed || reasons["node-slow"] != ReasonEvaluationFailed {
+		t.Errorf("expected events with the results of the nodes, but got %v", reasons)
+	}
+}
+
+func TestWatcherResumesTaintedNodes(t *testing.T) {
+	taintedNode := newNode("node-tainted", true)
+	taintedNode.Spec.Taints = []corev1.Taint{unevaluatedTaint}
+	clientset := fake.NewClientset(newNode("node-existing", true), taintedNode)
+	runs := make(chan []string, 2)
+	w := New(&config.Config{
+		NodeSelector: config.Selector{LabelSelector: "pool=general"},
+		Thresholds: config.Thresholds{
+			Suites: []config.SuiteThreshold{{MaxAverageLatency: time.Second}},
+		},
+		Watch: config.Watch{
+			BatchDelay: 10 * time.Millisecond,
+		},
+	}, zap.NewNop().Sugar(), k8s.NewFromClientset(clientset), func(c *config.Config) evaluator.TestRunnerInterface {
+		runs <- c.NodeSelector.Names
+		return &stubRunner{config: c}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	watchErr := make(chan error, 1)
+	go func() {
+		watchErr <- w.Watch(ctx)
+	}()
+	// The taint is removed even though tainting is disabled after the restart
+	waitFor(t, "the taint to be removed", func() bool {
+		return !hasUnevaluatedTaint(t, clientset, "node-tainted")
+	})
+	cancel()
+	err := <-watchErr
+	if err != nil {
+		t.Fatalf("failed to watch nodes: %v", err)
+	}
+	close(runs)
+
+	evaluatedNodes := []string{}
+	for run := range runs {
+		evaluatedNodes = append(evaluatedNodes, run...)
+	}
+	if len(evaluatedNodes) != 1 || evaluatedNodes[0] != "node-tainted" {
+		t.Errorf("expected only the tainted existing node to be evaluated, but got %v", evaluatedNodes)
+	}
+}
+
+func hasUnevaluatedTaint(t *testing.T, clientset *fake.Clientset, name string) bool {
+	node, err := clientset.CoreV1().Nodes().Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get node %s: %v", name, err)
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == UnevaluatedTaintKey {
+			return true
+		}
+	}
+	return false
+}
+
+func waitFor(t *testing.T, description string, condition func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", description)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}